@@ -5,23 +5,36 @@ import (
 	"time"
 
 	clientset "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned"
+	mapscheme "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/scheme"
 	informers "github.com/richardcase/vault-initializer/pkg/client/informers/externalversions"
 	"github.com/richardcase/vault-initializer/pkg/initializer"
+	"github.com/richardcase/vault-initializer/pkg/inject"
 	"github.com/richardcase/vault-initializer/pkg/signals"
+	"github.com/richardcase/vault-initializer/pkg/vaultclient"
 	"github.com/richardcase/vault-initializer/pkg/version"
+	"github.com/richardcase/vault-initializer/pkg/webhook"
 
 	"github.com/golang/glog"
 
 	corev1 "k8s.io/api/core/v1"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
+	agentName = "vault-initializer"
+
 	defaultInitializerName = "vault.initializer.kubernetes.io"
 	defaultConfigmap       = "vault-initializer"
 	defaultSecret          = "vault-initializer"
+	defaultWebhookAddress  = ":8443"
+
+	modeInitializer = "initializer"
+	modeWebhook     = "webhook"
 )
 
 var (
@@ -31,6 +44,10 @@ var (
 	configmap       string
 	secretName      string
 	masterURL       string
+	mode            string
+	webhookAddress  string
+	tlsCertFile     string
+	tlsKeyFile      string
 )
 
 func main() {
@@ -58,25 +75,76 @@ func main() {
 		glog.Fatalf("Error build vault map clientset: %s", err.Error())
 	}
 
+	config, err := inject.GetInitializerConfig(kubeClient, namespace, configmap)
+	if err != nil {
+		glog.Fatalf("Error reading initializer config: %s", err.Error())
+	}
+
+	secrets, err := inject.GetInitializerSecret(kubeClient, namespace, secretName)
+	if err != nil {
+		glog.Fatalf("Error reading initializer secret: %s", err.Error())
+	}
+
+	vaultClient, err := vaultclient.NewClient(config, secrets)
+	if err != nil {
+		glog.Fatalf("Error building vault client: %s", err.Error())
+	}
+
+	authenticator, err := vaultclient.CreateAuthenticator(config, secrets)
+	if err != nil {
+		glog.Fatalf("Error creating vault authenticator: %s", err.Error())
+	}
+
+	tokens := vaultclient.NewTokenManager(vaultClient, authenticator)
+	if err = tokens.Start(stopCH); err != nil {
+		glog.Fatalf("Error logging in to vault: %s", err.Error())
+	}
+
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Second*30)
 	mapInformerFactory := informers.NewSharedInformerFactory(mapClient, time.Second*30)
 
-	initializer := initializer.NewInitializer(
-		kubeClient,
-		mapClient,
-		kubeInformerFactory,
-		mapInformerFactory,
-		namespace,
-		configmap,
-		secretName,
-		initializerName,
-		stopCH)
-
-	go kubeInformerFactory.Start(stopCH)
-	go mapInformerFactory.Start(stopCH)
-
-	if err = initializer.Run(1, stopCH); err != nil {
-		glog.Fatalf("Error running initializer: %s", err.Error())
+	mapscheme.AddToScheme(scheme.Scheme)
+	glog.V(4).Info("Creating event broadcaster")
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(glog.Infof)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: agentName})
+
+	mapsInformer := mapInformerFactory.Vaultinit().V1alpha1().VaultMaps()
+	clusterMapsInformer := mapInformerFactory.Vaultinit().V1alpha1().ClusterVaultMaps()
+	injector := inject.NewInjector(kubeClient, mapClient, mapsInformer.Lister(), clusterMapsInformer.Lister(), vaultClient, tokens, config, recorder)
+	injector.Start(stopCH)
+
+	switch mode {
+	case modeWebhook:
+		server := webhook.NewServer(injector, mapsInformer.Informer().HasSynced, clusterMapsInformer.Informer().HasSynced)
+
+		go mapInformerFactory.Start(stopCH)
+
+		if err = server.Run(webhookAddress, tlsCertFile, tlsKeyFile, stopCH); err != nil {
+			glog.Fatalf("Error running webhook server: %s", err.Error())
+		}
+	case modeInitializer:
+		initializer := initializer.NewInitializer(
+			kubeClient,
+			mapClient,
+			kubeInformerFactory,
+			mapInformerFactory,
+			namespace,
+			config,
+			injector,
+			initializerName,
+			recorder,
+			stopCH)
+
+		go kubeInformerFactory.Start(stopCH)
+		go mapInformerFactory.Start(stopCH)
+
+		if err = initializer.Run(1, stopCH); err != nil {
+			glog.Fatalf("Error running initializer: %s", err.Error())
+		}
+	default:
+		glog.Fatalf("Invalid mode %s, expected %s or %s", mode, modeInitializer, modeWebhook)
 	}
 }
 
@@ -86,5 +154,9 @@ func init() {
 	flag.StringVar(&configmap, "configmap", defaultConfigmap, "The vault initializer configuration configmap")
 	flag.StringVar(&secretName, "secret", defaultSecret, "The vault initializer secret")
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Absolute path to the kubeconfig file. Only required if out-of-cluster.")
+	flag.StringVar(&mode, "mode", modeInitializer, "Whether to run as an initializer or as a mutating admission webhook (initializer or webhook)")
+	flag.StringVar(&webhookAddress, "webhook-address", defaultWebhookAddress, "The address the webhook server listens on")
+	flag.StringVar(&tlsCertFile, "tls-cert-file", "", "The TLS certificate file for the webhook server")
+	flag.StringVar(&tlsKeyFile, "tls-key-file", "", "The TLS private key file for the webhook server")
 	flag.StringVar(&masterURL, "master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 }
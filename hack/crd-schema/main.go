@@ -0,0 +1,29 @@
+// Command crd-schema writes the validation schemas generated from the API
+// types into the custom resource definitions
+package main
+
+import (
+	"flag"
+	"path/filepath"
+
+	"github.com/golang/glog"
+	"github.com/richardcase/vault-initializer/pkg/crd"
+)
+
+func main() {
+	dir := flag.String("dir", "artifacts/crd", "Directory of the custom resource definitions")
+	flag.Parse()
+
+	for file, versions := range crd.Schemas {
+		schemas := make(map[string]crd.JSONSchemaProps)
+		for version, schema := range versions {
+			schemas[version] = schema()
+		}
+
+		path := filepath.Join(*dir, file)
+		if err := crd.WriteSchema(path, schemas); err != nil {
+			glog.Fatalf("Error writing schema to %s: %v", path, err)
+		}
+		glog.Infof("Wrote schema to %s", path)
+	}
+}
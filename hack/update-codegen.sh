@@ -9,5 +9,5 @@ CODEGEN_PKG=${CODEGEN_PKG:-$(cd ${SCRIPT_ROOT}; ls -d -1 ./vendor/k8s.io/code-ge
 
 vendor/k8s.io/code-generator/generate-groups.sh all \
   github.com/richardcase/vault-initializer/pkg/client github.com/richardcase/vault-initializer/pkg/apis \
-  vaultinit:v1alpha1 \
+  vaultinit:v1alpha1,v1beta1 \
   --go-header-file ${SCRIPT_ROOT}/hack/custom-boilerplate.go.txt
\ No newline at end of file
@@ -0,0 +1,39 @@
+#!/bin/bash
+
+# Creates a self signed CA and a certificate for the webhook service, stores
+# them in the vault-webhook-certs secret and prints the CA bundle to use in
+# the MutatingWebhookConfiguration.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SERVICE=${SERVICE:-vault-webhook}
+NAMESPACE=${NAMESPACE:-default}
+SECRET=${SECRET:-vault-webhook-certs}
+
+TMPDIR=$(mktemp -d)
+trap "rm -rf ${TMPDIR}" EXIT
+
+openssl req -x509 -newkey rsa:2048 -nodes -days 365 \
+  -keyout ${TMPDIR}/ca-key.pem -out ${TMPDIR}/ca.pem -subj "/CN=${SERVICE}-ca"
+
+openssl req -newkey rsa:2048 -nodes \
+  -keyout ${TMPDIR}/key.pem -out ${TMPDIR}/server.csr -subj "/CN=${SERVICE}.${NAMESPACE}.svc"
+
+cat > ${TMPDIR}/server.ext <<EOT
+subjectAltName = DNS:${SERVICE},DNS:${SERVICE}.${NAMESPACE},DNS:${SERVICE}.${NAMESPACE}.svc
+EOT
+
+openssl x509 -req -days 365 -in ${TMPDIR}/server.csr \
+  -CA ${TMPDIR}/ca.pem -CAkey ${TMPDIR}/ca-key.pem -CAcreateserial \
+  -out ${TMPDIR}/cert.pem -extfile ${TMPDIR}/server.ext
+
+kubectl create secret generic ${SECRET} --namespace ${NAMESPACE} \
+  --from-file=cert.pem=${TMPDIR}/cert.pem \
+  --from-file=key.pem=${TMPDIR}/key.pem \
+  --dry-run -o yaml | kubectl apply -f -
+
+echo "CA bundle:"
+base64 < ${TMPDIR}/ca.pem | tr -d '\n'
+echo
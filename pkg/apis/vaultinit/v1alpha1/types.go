@@ -5,7 +5,6 @@ import (
 )
 
 // +genclient
-// +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // VaultMap defines a vault map resource
@@ -16,18 +15,78 @@ type VaultMap struct {
 	Status            MapStatus `json:"status"`
 }
 
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterVaultMap defines a cluster wide vault map resource. It applies to
+// workloads in any namespace that no VaultMap applies to.
+type ClusterVaultMap struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              MapSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterVaultMapList is a list of ClusterVaultMap resources
+type ClusterVaultMapList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ClusterVaultMap `json:"items"`
+}
+
 // MapSpec is the spec for a VaultMap resource
 type MapSpec struct {
-	VaultPathPattern       string `json:"vaultPathPattern"`
-	SecretsPublisher       string `json:"secretsPublisher"`
-	SecretsFilePathPattern string `json:"secretsFilePathPattern"`
-	SecretsFileNamePattern string `json:"secretsFileNamePattern"`
-	SecretNamePattern      string `json:"secretNamePattern"`
+	// Selector limits the workloads the map applies to by the labels of
+	// their pod template. A map without a selector applies to all workloads.
+	Selector         *metav1.LabelSelector `json:"selector,omitempty"`
+	VaultPathPattern string                `json:"vaultPathPattern,omitempty"`
+	VaultPaths       []VaultPath           `json:"vaultPaths,omitempty"`
+	ConflictPolicy   string                `json:"conflictPolicy,omitempty"`
+	KVVersion        string                `json:"kvVersion,omitempty"`
+	SecretsPublisher string                `json:"secretsPublisher"`
+	// The secret name and file patterns are only needed for the volume publisher
+	SecretsFilePathPattern string `json:"secretsFilePathPattern,omitempty"`
+	SecretsFileNamePattern string `json:"secretsFileNamePattern,omitempty"`
+	SecretNamePattern      string `json:"secretNamePattern,omitempty"`
+	FlattenSecrets         bool   `json:"flattenSecrets,omitempty"`
+	InjectInitContainers   bool   `json:"injectInitContainers,omitempty"`
+	// FailurePolicy overrides the failure policy of the initializer for
+	// workloads using the map, either Fail or Ignore
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+// VaultPath is a vault path that secrets are read from. The KV version and
+// publisher default to the ones in the MapSpec.
+type VaultPath struct {
+	PathPattern      string `json:"pathPattern"`
+	KVVersion        string `json:"kvVersion,omitempty"`
+	KeyPrefix        string `json:"keyPrefix,omitempty"`
+	SecretsPublisher string `json:"secretsPublisher,omitempty"`
 }
 
 // MapStatus is the status fro the the VaultMap resource
 type MapStatus struct {
-	PodsWithSecrets []string `json:"podsWithSecrets"`
+	// PodsWithSecrets is no longer set.
+	// Deprecated: Use Workloads instead.
+	PodsWithSecrets []string `json:"podsWithSecrets,omitempty"`
+	// Workloads are the workloads that secrets have been injected into using
+	// the map, most recent last
+	Workloads         []WorkloadReference `json:"workloads,omitempty"`
+	LastInjectionTime *metav1.Time        `json:"lastInjectionTime,omitempty"`
+	// LastError is the error from the last injection that failed, it is
+	// cleared when an injection succeeds
+	LastError          string `json:"lastError,omitempty"`
+	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+}
+
+// WorkloadReference refers to a workload in the namespace of a VaultMap
+type WorkloadReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,191 @@
+package v1beta1
+
+import (
+	"strings"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VaultPathsAnnotation is set when converting a v1alpha1 vault map that only
+// has vault paths and no vault path pattern, so that converting it back
+// leaves the first path in the vault paths
+const VaultPathsAnnotation = "vaultinit.k8s.io/v1alpha1-vault-paths"
+
+// PodsWithSecretsAnnotation holds the deprecated pods with secrets of a
+// v1alpha1 vault map status, which v1beta1 doesn't have, as a comma
+// separated list so converting it back doesn't lose them
+const PodsWithSecretsAnnotation = "vaultinit.k8s.io/v1alpha1-pods-with-secrets"
+
+// VaultMapFromV1alpha1 converts a v1alpha1 vault map to v1beta1
+func VaultMapFromV1alpha1(in *v1alpha1.VaultMap) *VaultMap {
+	out := &VaultMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "VaultMap"},
+		Spec:     mapSpecFromV1alpha1(&in.Spec),
+		Status:   mapStatusFromV1alpha1(&in.Status),
+	}
+	metaFromV1alpha1(&in.ObjectMeta, &in.Spec, &out.ObjectMeta)
+	if len(in.Status.PodsWithSecrets) > 0 {
+		setAnnotation(&out.ObjectMeta, PodsWithSecretsAnnotation, strings.Join(in.Status.PodsWithSecrets, ","))
+	}
+	return out
+}
+
+// VaultMapToV1alpha1 converts a v1beta1 vault map to v1alpha1
+func VaultMapToV1alpha1(in *VaultMap) *v1alpha1.VaultMap {
+	out := &v1alpha1.VaultMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "VaultMap"},
+		Spec:     mapSpecToV1alpha1(&in.Spec, &in.ObjectMeta),
+		Status:   mapStatusToV1alpha1(&in.Status),
+	}
+	if pods := in.Annotations[PodsWithSecretsAnnotation]; pods != "" {
+		out.Status.PodsWithSecrets = strings.Split(pods, ",")
+	}
+	metaToV1alpha1(&in.ObjectMeta, &out.ObjectMeta)
+	return out
+}
+
+// ClusterVaultMapFromV1alpha1 converts a v1alpha1 cluster vault map to v1beta1
+func ClusterVaultMapFromV1alpha1(in *v1alpha1.ClusterVaultMap) *ClusterVaultMap {
+	out := &ClusterVaultMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "ClusterVaultMap"},
+		Spec:     mapSpecFromV1alpha1(&in.Spec),
+	}
+	metaFromV1alpha1(&in.ObjectMeta, &in.Spec, &out.ObjectMeta)
+	return out
+}
+
+// ClusterVaultMapToV1alpha1 converts a v1beta1 cluster vault map to v1alpha1
+func ClusterVaultMapToV1alpha1(in *ClusterVaultMap) *v1alpha1.ClusterVaultMap {
+	out := &v1alpha1.ClusterVaultMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "ClusterVaultMap"},
+		Spec:     mapSpecToV1alpha1(&in.Spec, &in.ObjectMeta),
+	}
+	metaToV1alpha1(&in.ObjectMeta, &out.ObjectMeta)
+	return out
+}
+
+// metaFromV1alpha1 copies the object meta of a v1alpha1 vault map, recording
+// whether it only has vault paths
+func metaFromV1alpha1(in *metav1.ObjectMeta, spec *v1alpha1.MapSpec, out *metav1.ObjectMeta) {
+	in.DeepCopyInto(out)
+	if spec.VaultPathPattern == "" && len(spec.VaultPaths) > 0 {
+		setAnnotation(out, VaultPathsAnnotation, "true")
+	}
+}
+
+func setAnnotation(meta *metav1.ObjectMeta, key, value string) {
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[key] = value
+}
+
+// metaToV1alpha1 copies the object meta of a vault map to v1alpha1 without
+// the annotations added by the conversion
+func metaToV1alpha1(in *metav1.ObjectMeta, out *metav1.ObjectMeta) {
+	in.DeepCopyInto(out)
+	for _, annotation := range []string{VaultPathsAnnotation, PodsWithSecretsAnnotation} {
+		delete(out.Annotations, annotation)
+	}
+	if len(out.Annotations) == 0 {
+		out.Annotations = nil
+	}
+}
+
+// mapSpecFromV1alpha1 converts a v1alpha1 spec. The vault path pattern
+// becomes the first path and the volume publisher options are only set if
+// any of them are.
+func mapSpecFromV1alpha1(in *v1alpha1.MapSpec) MapSpec {
+	out := MapSpec{
+		Selector:             in.Selector.DeepCopy(),
+		ConflictPolicy:       in.ConflictPolicy,
+		KVVersion:            in.KVVersion,
+		Publisher:            PublisherSpec{Type: in.SecretsPublisher},
+		FlattenSecrets:       in.FlattenSecrets,
+		InjectInitContainers: in.InjectInitContainers,
+		FailurePolicy:        in.FailurePolicy,
+	}
+
+	if in.VaultPathPattern != "" {
+		out.Paths = append(out.Paths, VaultPath{Path: in.VaultPathPattern})
+	}
+	for _, path := range in.VaultPaths {
+		out.Paths = append(out.Paths, VaultPath{
+			Path:      path.PathPattern,
+			KVVersion: path.KVVersion,
+			KeyPrefix: path.KeyPrefix,
+			Publisher: path.SecretsPublisher,
+		})
+	}
+
+	if in.SecretNamePattern != "" || in.SecretsFilePathPattern != "" || in.SecretsFileNamePattern != "" {
+		out.Publisher.Volume = &VolumePublisherSpec{
+			SecretNamePattern: in.SecretNamePattern,
+			FilePathPattern:   in.SecretsFilePathPattern,
+			FileNamePattern:   in.SecretsFileNamePattern,
+		}
+	}
+	return out
+}
+
+// mapSpecToV1alpha1 converts a spec to v1alpha1. The first path becomes the
+// vault path pattern unless it has options of its own, which the pattern
+// can't have, or the map was converted from a v1alpha1 map without one.
+func mapSpecToV1alpha1(in *MapSpec, meta *metav1.ObjectMeta) v1alpha1.MapSpec {
+	out := v1alpha1.MapSpec{
+		Selector:             in.Selector.DeepCopy(),
+		ConflictPolicy:       in.ConflictPolicy,
+		KVVersion:            in.KVVersion,
+		SecretsPublisher:     in.Publisher.Type,
+		FlattenSecrets:       in.FlattenSecrets,
+		InjectInitContainers: in.InjectInitContainers,
+		FailurePolicy:        in.FailurePolicy,
+	}
+
+	paths := in.Paths
+	_, vaultPathsOnly := meta.Annotations[VaultPathsAnnotation]
+	if len(paths) > 0 && !vaultPathsOnly && paths[0].KVVersion == "" && paths[0].KeyPrefix == "" && paths[0].Publisher == "" {
+		out.VaultPathPattern = paths[0].Path
+		paths = paths[1:]
+	}
+	for _, path := range paths {
+		out.VaultPaths = append(out.VaultPaths, v1alpha1.VaultPath{
+			PathPattern:      path.Path,
+			KVVersion:        path.KVVersion,
+			KeyPrefix:        path.KeyPrefix,
+			SecretsPublisher: path.Publisher,
+		})
+	}
+
+	if in.Publisher.Volume != nil {
+		out.SecretNamePattern = in.Publisher.Volume.SecretNamePattern
+		out.SecretsFilePathPattern = in.Publisher.Volume.FilePathPattern
+		out.SecretsFileNamePattern = in.Publisher.Volume.FileNamePattern
+	}
+	return out
+}
+
+func mapStatusFromV1alpha1(in *v1alpha1.MapStatus) MapStatus {
+	out := MapStatus{
+		LastInjectionTime:  in.LastInjectionTime.DeepCopy(),
+		LastError:          in.LastError,
+		ObservedGeneration: in.ObservedGeneration,
+	}
+	for _, workload := range in.Workloads {
+		out.Workloads = append(out.Workloads, WorkloadReference{Kind: workload.Kind, Name: workload.Name})
+	}
+	return out
+}
+
+func mapStatusToV1alpha1(in *MapStatus) v1alpha1.MapStatus {
+	out := v1alpha1.MapStatus{
+		LastInjectionTime:  in.LastInjectionTime.DeepCopy(),
+		LastError:          in.LastError,
+		ObservedGeneration: in.ObservedGeneration,
+	}
+	for _, workload := range in.Workloads {
+		out.Workloads = append(out.Workloads, v1alpha1.WorkloadReference{Kind: workload.Kind, Name: workload.Name})
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVaultMapRoundTrip(t *testing.T) {
+	now := metav1.NewTime(time.Unix(1500000000, 0))
+	tests := []*v1alpha1.VaultMap{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "default-vaultmap"},
+			Spec: v1alpha1.MapSpec{
+				VaultPathPattern:       "/v1/secret/{{.Namespace}}/{{.ContainerName}}",
+				SecretsPublisher:       "volume",
+				SecretsFilePathPattern: "/",
+				SecretsFileNamePattern: "config.json",
+				SecretNamePattern:      "{{.Namespace}}.{{.ContainerName}}",
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "payments", Labels: map[string]string{"team": "payments"}},
+			Spec: v1alpha1.MapSpec{
+				Selector:         &metav1.LabelSelector{MatchLabels: map[string]string{"app": "payments"}},
+				VaultPathPattern: "/v1/secret/{{.Namespace}}/{{.ContainerName}}",
+				VaultPaths: []v1alpha1.VaultPath{
+					{PathPattern: "/v1/secret/shared/db", KeyPrefix: "db_"},
+					{PathPattern: "/v1/secret/shared/apikey", KVVersion: "1", SecretsPublisher: "env"},
+				},
+				ConflictPolicy:       "last",
+				KVVersion:            "2",
+				SecretsPublisher:     "env",
+				FlattenSecrets:       true,
+				InjectInitContainers: true,
+				FailurePolicy:        "Ignore",
+			},
+			Status: v1alpha1.MapStatus{
+				PodsWithSecrets:    []string{"payments-5d8f7c9b6-x2x7q", "payments-5d8f7c9b6-k8s4m"},
+				Workloads:          []v1alpha1.WorkloadReference{{Kind: "Deployment", Name: "payments"}},
+				LastInjectionTime:  &now,
+				LastError:          "Vault sealed",
+				ObservedGeneration: 3,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shared", Name: "shared", Annotations: map[string]string{"owner": "platform"}},
+			Spec: v1alpha1.MapSpec{
+				VaultPaths: []v1alpha1.VaultPath{
+					{PathPattern: "/v1/secret/shared/db"},
+					{PathPattern: "/v1/secret/shared/apikey", KeyPrefix: "api_"},
+				},
+				SecretsPublisher: "env",
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shared", Name: "db"},
+			Spec: v1alpha1.MapSpec{
+				VaultPaths:       []v1alpha1.VaultPath{{PathPattern: "/v1/secret/shared/db"}},
+				SecretsPublisher: "env",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		converted := VaultMapToV1alpha1(VaultMapFromV1alpha1(test))
+		converted.TypeMeta = test.TypeMeta
+		if !reflect.DeepEqual(converted, test) {
+			t.Errorf("Got unexpected vault map after round trip: %v, expected %v", converted, test)
+		}
+	}
+}
+
+func TestVaultMapFromV1alpha1(t *testing.T) {
+	vaultmap := &v1alpha1.VaultMap{
+		Spec: v1alpha1.MapSpec{
+			VaultPathPattern:  "/v1/secret/{{.Namespace}}/{{.ContainerName}}",
+			VaultPaths:        []v1alpha1.VaultPath{{PathPattern: "/v1/secret/shared/db", KeyPrefix: "db_"}},
+			SecretsPublisher:  "volume",
+			SecretNamePattern: "{{.Namespace}}.{{.ContainerName}}",
+		},
+	}
+
+	converted := VaultMapFromV1alpha1(vaultmap)
+	if converted.APIVersion != "vaultinit.k8s.io/v1beta1" || converted.Kind != "VaultMap" {
+		t.Errorf("Got unexpected type: %v", converted.TypeMeta)
+	}
+	expected := []VaultPath{
+		{Path: "/v1/secret/{{.Namespace}}/{{.ContainerName}}"},
+		{Path: "/v1/secret/shared/db", KeyPrefix: "db_"},
+	}
+	if !reflect.DeepEqual(converted.Spec.Paths, expected) {
+		t.Errorf("Got unexpected paths: %v", converted.Spec.Paths)
+	}
+	if converted.Spec.Publisher.Type != "volume" || converted.Spec.Publisher.Volume == nil || converted.Spec.Publisher.Volume.SecretNamePattern != "{{.Namespace}}.{{.ContainerName}}" {
+		t.Errorf("Got unexpected publisher: %v", converted.Spec.Publisher)
+	}
+}
+
+func TestVaultMapToV1alpha1FirstPathWithOptions(t *testing.T) {
+	vaultmap := &VaultMap{
+		Spec: MapSpec{
+			Paths:     []VaultPath{{Path: "/v1/secret/shared/db", KeyPrefix: "db_"}},
+			Publisher: PublisherSpec{Type: "env"},
+		},
+	}
+
+	converted := VaultMapToV1alpha1(vaultmap)
+	if converted.Spec.VaultPathPattern != "" {
+		t.Errorf("Got unexpected vault path pattern: %s", converted.Spec.VaultPathPattern)
+	}
+	if len(converted.Spec.VaultPaths) != 1 || converted.Spec.VaultPaths[0].KeyPrefix != "db_" {
+		t.Errorf("Got unexpected vault paths: %v", converted.Spec.VaultPaths)
+	}
+	if !reflect.DeepEqual(VaultMapFromV1alpha1(converted).Spec, vaultmap.Spec) {
+		t.Errorf("Got unexpected spec after round trip: %v", VaultMapFromV1alpha1(converted).Spec)
+	}
+}
+
+func TestClusterVaultMapRoundTrip(t *testing.T) {
+	clusterMap := &v1alpha1.ClusterVaultMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: v1alpha1.MapSpec{
+			VaultPathPattern: "/v1/secret/{{.Namespace}}/{{.ContainerName}}",
+			SecretsPublisher: "env",
+		},
+	}
+
+	converted := ClusterVaultMapToV1alpha1(ClusterVaultMapFromV1alpha1(clusterMap))
+	converted.TypeMeta = clusterMap.TypeMeta
+	if !reflect.DeepEqual(converted, clusterMap) {
+		t.Errorf("Got unexpected cluster vault map after round trip: %v", converted)
+	}
+}
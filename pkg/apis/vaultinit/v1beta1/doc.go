@@ -0,0 +1,5 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1beta1 is the v1beta1 version of the API.
+// +groupName=vaultinit.k8s.io
+package v1beta1
@@ -0,0 +1,109 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VaultMap defines a vault map resource
+type VaultMap struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              MapSpec   `json:"spec"`
+	Status            MapStatus `json:"status"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterVaultMap defines a cluster wide vault map resource. It applies to
+// workloads in any namespace that no VaultMap applies to.
+type ClusterVaultMap struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              MapSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterVaultMapList is a list of ClusterVaultMap resources
+type ClusterVaultMapList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ClusterVaultMap `json:"items"`
+}
+
+// MapSpec is the spec for a VaultMap resource
+type MapSpec struct {
+	// Selector limits the workloads the map applies to by the labels of
+	// their pod template. A map without a selector applies to all workloads.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Paths are the vault paths secrets are read from. Their secrets are
+	// merged in order using the conflict policy.
+	Paths                []VaultPath   `json:"paths"`
+	ConflictPolicy       string        `json:"conflictPolicy,omitempty"`
+	KVVersion            string        `json:"kvVersion,omitempty"`
+	Publisher            PublisherSpec `json:"publisher"`
+	FlattenSecrets       bool          `json:"flattenSecrets,omitempty"`
+	InjectInitContainers bool          `json:"injectInitContainers,omitempty"`
+	// FailurePolicy overrides the failure policy of the initializer for
+	// workloads using the map, either Fail or Ignore
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+// VaultPath is a vault path that secrets are read from. The KV version and
+// publisher default to the ones in the MapSpec.
+type VaultPath struct {
+	Path      string `json:"path"`
+	KVVersion string `json:"kvVersion,omitempty"`
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+}
+
+// PublisherSpec is how secrets are published into containers
+type PublisherSpec struct {
+	// Type is the publisher, either env or volume
+	Type string `json:"type"`
+	// Volume is the configuration of the volume publisher
+	Volume *VolumePublisherSpec `json:"volume,omitempty"`
+}
+
+// VolumePublisherSpec is the configuration of the volume publisher
+type VolumePublisherSpec struct {
+	SecretNamePattern string `json:"secretNamePattern"`
+	FilePathPattern   string `json:"filePathPattern"`
+	FileNamePattern   string `json:"fileNamePattern"`
+}
+
+// MapStatus is the status for the VaultMap resource
+type MapStatus struct {
+	// Workloads are the workloads that secrets have been injected into using
+	// the map, most recent last
+	Workloads         []WorkloadReference `json:"workloads,omitempty"`
+	LastInjectionTime *metav1.Time        `json:"lastInjectionTime,omitempty"`
+	// LastError is the error from the last injection that failed, it is
+	// cleared when an injection succeeds
+	LastError          string `json:"lastError,omitempty"`
+	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+}
+
+// WorkloadReference refers to a workload in the namespace of a VaultMap
+type WorkloadReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VaultMapList is a list of VaultMap resources
+type VaultMapList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []VaultMap `json:"items"`
+}
@@ -18,6 +18,7 @@ package versioned
 import (
 	glog "github.com/golang/glog"
 	vaultinitv1alpha1 "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/typed/vaultinit/v1alpha1"
+	vaultinitv1beta1 "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/typed/vaultinit/v1beta1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -26,6 +27,7 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	VaultinitV1alpha1() vaultinitv1alpha1.VaultinitV1alpha1Interface
+	VaultinitV1beta1() vaultinitv1beta1.VaultinitV1beta1Interface
 	// Deprecated: please explicitly pick a version if possible.
 	Vaultinit() vaultinitv1alpha1.VaultinitV1alpha1Interface
 }
@@ -35,6 +37,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	vaultinitV1alpha1 *vaultinitv1alpha1.VaultinitV1alpha1Client
+	vaultinitV1beta1  *vaultinitv1beta1.VaultinitV1beta1Client
 }
 
 // VaultinitV1alpha1 retrieves the VaultinitV1alpha1Client
@@ -42,6 +45,11 @@ func (c *Clientset) VaultinitV1alpha1() vaultinitv1alpha1.VaultinitV1alpha1Inter
 	return c.vaultinitV1alpha1
 }
 
+// VaultinitV1beta1 retrieves the VaultinitV1beta1Client
+func (c *Clientset) VaultinitV1beta1() vaultinitv1beta1.VaultinitV1beta1Interface {
+	return c.vaultinitV1beta1
+}
+
 // Deprecated: Vaultinit retrieves the default version of VaultinitClient.
 // Please explicitly pick a version.
 func (c *Clientset) Vaultinit() vaultinitv1alpha1.VaultinitV1alpha1Interface {
@@ -68,6 +76,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.vaultinitV1beta1, err = vaultinitv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -82,6 +94,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.vaultinitV1alpha1 = vaultinitv1alpha1.NewForConfigOrDie(c)
+	cs.vaultinitV1beta1 = vaultinitv1beta1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -91,6 +104,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.vaultinitV1alpha1 = vaultinitv1alpha1.New(c)
+	cs.vaultinitV1beta1 = vaultinitv1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
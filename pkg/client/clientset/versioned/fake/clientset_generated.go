@@ -19,6 +19,8 @@ import (
 	clientset "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned"
 	vaultinitv1alpha1 "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/typed/vaultinit/v1alpha1"
 	fakevaultinitv1alpha1 "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/typed/vaultinit/v1alpha1/fake"
+	vaultinitv1beta1 "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/typed/vaultinit/v1beta1"
+	fakevaultinitv1beta1 "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/typed/vaultinit/v1beta1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -64,6 +66,11 @@ func (c *Clientset) VaultinitV1alpha1() vaultinitv1alpha1.VaultinitV1alpha1Inter
 	return &fakevaultinitv1alpha1.FakeVaultinitV1alpha1{Fake: &c.Fake}
 }
 
+// VaultinitV1beta1 retrieves the VaultinitV1beta1Client
+func (c *Clientset) VaultinitV1beta1() vaultinitv1beta1.VaultinitV1beta1Interface {
+	return &fakevaultinitv1beta1.FakeVaultinitV1beta1{Fake: &c.Fake}
+}
+
 // Vaultinit retrieves the VaultinitV1alpha1Client
 func (c *Clientset) Vaultinit() vaultinitv1alpha1.VaultinitV1alpha1Interface {
 	return &fakevaultinitv1alpha1.FakeVaultinitV1alpha1{Fake: &c.Fake}
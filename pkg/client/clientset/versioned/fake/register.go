@@ -17,6 +17,7 @@ package fake
 
 import (
 	vaultinitv1alpha1 "github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	vaultinitv1beta1 "github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -48,5 +49,6 @@ func init() {
 // correctly.
 func AddToScheme(scheme *runtime.Scheme) {
 	vaultinitv1alpha1.AddToScheme(scheme)
+	vaultinitv1beta1.AddToScheme(scheme)
 
 }
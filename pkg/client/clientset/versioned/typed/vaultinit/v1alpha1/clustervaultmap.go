@@ -0,0 +1,144 @@
+/*
+Copyright 2017 The Vault Initializer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	scheme "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ClusterVaultMapsGetter has a method to return a ClusterVaultMapInterface.
+// A group's client should implement this interface.
+type ClusterVaultMapsGetter interface {
+	ClusterVaultMaps() ClusterVaultMapInterface
+}
+
+// ClusterVaultMapInterface has methods to work with ClusterVaultMap resources.
+type ClusterVaultMapInterface interface {
+	Create(*v1alpha1.ClusterVaultMap) (*v1alpha1.ClusterVaultMap, error)
+	Update(*v1alpha1.ClusterVaultMap) (*v1alpha1.ClusterVaultMap, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.ClusterVaultMap, error)
+	List(opts v1.ListOptions) (*v1alpha1.ClusterVaultMapList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterVaultMap, err error)
+	ClusterVaultMapExpansion
+}
+
+// clusterVaultMaps implements ClusterVaultMapInterface
+type clusterVaultMaps struct {
+	client rest.Interface
+}
+
+// newClusterVaultMaps returns a ClusterVaultMaps
+func newClusterVaultMaps(c *VaultinitV1alpha1Client) *clusterVaultMaps {
+	return &clusterVaultMaps{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the clusterVaultMap, and returns the corresponding clusterVaultMap object, and an error if there is any.
+func (c *clusterVaultMaps) Get(name string, options v1.GetOptions) (result *v1alpha1.ClusterVaultMap, err error) {
+	result = &v1alpha1.ClusterVaultMap{}
+	err = c.client.Get().
+		Resource("clustervaultmaps").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ClusterVaultMaps that match those selectors.
+func (c *clusterVaultMaps) List(opts v1.ListOptions) (result *v1alpha1.ClusterVaultMapList, err error) {
+	result = &v1alpha1.ClusterVaultMapList{}
+	err = c.client.Get().
+		Resource("clustervaultmaps").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested clusterVaultMaps.
+func (c *clusterVaultMaps) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Resource("clustervaultmaps").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a clusterVaultMap and creates it.  Returns the server's representation of the clusterVaultMap, and an error, if there is any.
+func (c *clusterVaultMaps) Create(clusterVaultMap *v1alpha1.ClusterVaultMap) (result *v1alpha1.ClusterVaultMap, err error) {
+	result = &v1alpha1.ClusterVaultMap{}
+	err = c.client.Post().
+		Resource("clustervaultmaps").
+		Body(clusterVaultMap).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a clusterVaultMap and updates it. Returns the server's representation of the clusterVaultMap, and an error, if there is any.
+func (c *clusterVaultMaps) Update(clusterVaultMap *v1alpha1.ClusterVaultMap) (result *v1alpha1.ClusterVaultMap, err error) {
+	result = &v1alpha1.ClusterVaultMap{}
+	err = c.client.Put().
+		Resource("clustervaultmaps").
+		Name(clusterVaultMap.Name).
+		Body(clusterVaultMap).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the clusterVaultMap and deletes it. Returns an error if one occurs.
+func (c *clusterVaultMaps) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("clustervaultmaps").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *clusterVaultMaps) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Resource("clustervaultmaps").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched clusterVaultMap.
+func (c *clusterVaultMaps) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterVaultMap, err error) {
+	result = &v1alpha1.ClusterVaultMap{}
+	err = c.client.Patch(pt).
+		Resource("clustervaultmaps").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
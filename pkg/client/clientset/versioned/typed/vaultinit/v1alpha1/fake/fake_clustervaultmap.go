@@ -0,0 +1,117 @@
+/*
+Copyright 2017 The Vault Initializer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package fake
+
+import (
+	v1alpha1 "github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeClusterVaultMaps implements ClusterVaultMapInterface
+type FakeClusterVaultMaps struct {
+	Fake *FakeVaultinitV1alpha1
+}
+
+var clustervaultmapsResource = schema.GroupVersionResource{Group: "vaultinit.k8s.io", Version: "v1alpha1", Resource: "clustervaultmaps"}
+
+var clustervaultmapsKind = schema.GroupVersionKind{Group: "vaultinit.k8s.io", Version: "v1alpha1", Kind: "ClusterVaultMap"}
+
+// Get takes name of the clusterVaultMap, and returns the corresponding clusterVaultMap object, and an error if there is any.
+func (c *FakeClusterVaultMaps) Get(name string, options v1.GetOptions) (result *v1alpha1.ClusterVaultMap, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(clustervaultmapsResource, name), &v1alpha1.ClusterVaultMap{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterVaultMap), err
+}
+
+// List takes label and field selectors, and returns the list of ClusterVaultMaps that match those selectors.
+func (c *FakeClusterVaultMaps) List(opts v1.ListOptions) (result *v1alpha1.ClusterVaultMapList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(clustervaultmapsResource, clustervaultmapsKind, opts), &v1alpha1.ClusterVaultMapList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.ClusterVaultMapList{}
+	for _, item := range obj.(*v1alpha1.ClusterVaultMapList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested clusterVaultMaps.
+func (c *FakeClusterVaultMaps) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(clustervaultmapsResource, opts))
+}
+
+// Create takes the representation of a clusterVaultMap and creates it.  Returns the server's representation of the clusterVaultMap, and an error, if there is any.
+func (c *FakeClusterVaultMaps) Create(clusterVaultMap *v1alpha1.ClusterVaultMap) (result *v1alpha1.ClusterVaultMap, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(clustervaultmapsResource, clusterVaultMap), &v1alpha1.ClusterVaultMap{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterVaultMap), err
+}
+
+// Update takes the representation of a clusterVaultMap and updates it. Returns the server's representation of the clusterVaultMap, and an error, if there is any.
+func (c *FakeClusterVaultMaps) Update(clusterVaultMap *v1alpha1.ClusterVaultMap) (result *v1alpha1.ClusterVaultMap, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(clustervaultmapsResource, clusterVaultMap), &v1alpha1.ClusterVaultMap{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterVaultMap), err
+}
+
+// Delete takes name of the clusterVaultMap and deletes it. Returns an error if one occurs.
+func (c *FakeClusterVaultMaps) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(clustervaultmapsResource, name), &v1alpha1.ClusterVaultMap{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeClusterVaultMaps) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(clustervaultmapsResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.ClusterVaultMapList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched clusterVaultMap.
+func (c *FakeClusterVaultMaps) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterVaultMap, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(clustervaultmapsResource, name, data, subresources...), &v1alpha1.ClusterVaultMap{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterVaultMap), err
+}
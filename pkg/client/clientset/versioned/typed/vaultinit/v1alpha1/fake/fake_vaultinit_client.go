@@ -25,6 +25,10 @@ type FakeVaultinitV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeVaultinitV1alpha1) ClusterVaultMaps() v1alpha1.ClusterVaultMapInterface {
+	return &FakeClusterVaultMaps{c}
+}
+
 func (c *FakeVaultinitV1alpha1) VaultMaps(namespace string) v1alpha1.VaultMapInterface {
 	return &FakeVaultMaps{c, namespace}
 }
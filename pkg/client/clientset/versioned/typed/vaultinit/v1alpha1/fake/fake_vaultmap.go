@@ -97,6 +97,18 @@ func (c *FakeVaultMaps) Update(vaultMap *v1alpha1.VaultMap) (result *v1alpha1.Va
 	return obj.(*v1alpha1.VaultMap), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVaultMaps) UpdateStatus(vaultMap *v1alpha1.VaultMap) (*v1alpha1.VaultMap, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(vaultmapsResource, "status", c.ns, vaultMap), &v1alpha1.VaultMap{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultMap), err
+}
+
 // Delete takes name of the vaultMap and deletes it. Returns an error if one occurs.
 func (c *FakeVaultMaps) Delete(name string, options *v1.DeleteOptions) error {
 	_, err := c.Fake.
@@ -24,6 +24,7 @@ import (
 
 type VaultinitV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	ClusterVaultMapsGetter
 	VaultMapsGetter
 }
 
@@ -32,6 +33,10 @@ type VaultinitV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *VaultinitV1alpha1Client) ClusterVaultMaps() ClusterVaultMapInterface {
+	return newClusterVaultMaps(c)
+}
+
 func (c *VaultinitV1alpha1Client) VaultMaps(namespace string) VaultMapInterface {
 	return newVaultMaps(c, namespace)
 }
@@ -34,6 +34,7 @@ type VaultMapsGetter interface {
 type VaultMapInterface interface {
 	Create(*v1alpha1.VaultMap) (*v1alpha1.VaultMap, error)
 	Update(*v1alpha1.VaultMap) (*v1alpha1.VaultMap, error)
+	UpdateStatus(*v1alpha1.VaultMap) (*v1alpha1.VaultMap, error)
 	Delete(name string, options *v1.DeleteOptions) error
 	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
 	Get(name string, options v1.GetOptions) (*v1alpha1.VaultMap, error)
@@ -117,6 +118,22 @@ func (c *vaultMaps) Update(vaultMap *v1alpha1.VaultMap) (result *v1alpha1.VaultM
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *vaultMaps) UpdateStatus(vaultMap *v1alpha1.VaultMap) (result *v1alpha1.VaultMap, err error) {
+	result = &v1alpha1.VaultMap{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("vaultmaps").
+		Name(vaultMap.Name).
+		SubResource("status").
+		Body(vaultMap).
+		Do().
+		Into(result)
+	return
+}
+
 // Delete takes name of the vaultMap and deletes it. Returns an error if one occurs.
 func (c *vaultMaps) Delete(name string, options *v1.DeleteOptions) error {
 	return c.client.Delete().
@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	v1alpha1 "github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	v1beta1 "github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -53,9 +54,17 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=vaultinit.k8s.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("clustervaultmaps"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Vaultinit().V1alpha1().ClusterVaultMaps().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("vaultmaps"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Vaultinit().V1alpha1().VaultMaps().Informer()}, nil
 
+		// Group=vaultinit.k8s.io, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("clustervaultmaps"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Vaultinit().V1beta1().ClusterVaultMaps().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("vaultmaps"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Vaultinit().V1beta1().VaultMaps().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// ClusterVaultMaps returns a ClusterVaultMapInformer.
+	ClusterVaultMaps() ClusterVaultMapInformer
 	// VaultMaps returns a VaultMapInformer.
 	VaultMaps() VaultMapInformer
 }
@@ -39,6 +41,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// ClusterVaultMaps returns a ClusterVaultMapInformer.
+func (v *version) ClusterVaultMaps() ClusterVaultMapInformer {
+	return &clusterVaultMapInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // VaultMaps returns a VaultMapInformer.
 func (v *version) VaultMaps() VaultMapInformer {
 	return &vaultMapInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -0,0 +1,88 @@
+/*
+Copyright 2017 The Vault Initializer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file was automatically generated by informer-gen
+
+package v1beta1
+
+import (
+	time "time"
+
+	vaultinit_v1beta1 "github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1beta1"
+	versioned "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/richardcase/vault-initializer/pkg/client/informers/externalversions/internalinterfaces"
+	v1beta1 "github.com/richardcase/vault-initializer/pkg/client/listers/vaultinit/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// ClusterVaultMapInformer provides access to a shared informer and lister for
+// ClusterVaultMaps.
+type ClusterVaultMapInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1beta1.ClusterVaultMapLister
+}
+
+type clusterVaultMapInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewClusterVaultMapInformer constructs a new informer for ClusterVaultMap type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewClusterVaultMapInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredClusterVaultMapInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredClusterVaultMapInformer constructs a new informer for ClusterVaultMap type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredClusterVaultMapInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VaultinitV1beta1().ClusterVaultMaps().List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VaultinitV1beta1().ClusterVaultMaps().Watch(options)
+			},
+		},
+		&vaultinit_v1beta1.ClusterVaultMap{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *clusterVaultMapInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredClusterVaultMapInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *clusterVaultMapInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&vaultinit_v1beta1.ClusterVaultMap{}, f.defaultInformer)
+}
+
+func (f *clusterVaultMapInformer) Lister() v1beta1.ClusterVaultMapLister {
+	return v1beta1.NewClusterVaultMapLister(f.Informer().GetIndexer())
+}
@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1alpha1
 
+// ClusterVaultMapListerExpansion allows custom methods to be added to
+// ClusterVaultMapLister.
+type ClusterVaultMapListerExpansion interface{}
+
 // VaultMapListerExpansion allows custom methods to be added to
 // VaultMapLister.
 type VaultMapListerExpansion interface{}
@@ -0,0 +1,65 @@
+/*
+Copyright 2017 The Vault Initializer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file was automatically generated by lister-gen
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ClusterVaultMapLister helps list ClusterVaultMaps.
+type ClusterVaultMapLister interface {
+	// List lists all ClusterVaultMaps in the indexer.
+	List(selector labels.Selector) (ret []*v1beta1.ClusterVaultMap, err error)
+	// Get retrieves the ClusterVaultMap from the index for a given name.
+	Get(name string) (*v1beta1.ClusterVaultMap, error)
+	ClusterVaultMapListerExpansion
+}
+
+// clusterVaultMapLister implements the ClusterVaultMapLister interface.
+type clusterVaultMapLister struct {
+	indexer cache.Indexer
+}
+
+// NewClusterVaultMapLister returns a new ClusterVaultMapLister.
+func NewClusterVaultMapLister(indexer cache.Indexer) ClusterVaultMapLister {
+	return &clusterVaultMapLister{indexer: indexer}
+}
+
+// List lists all ClusterVaultMaps in the indexer.
+func (s *clusterVaultMapLister) List(selector labels.Selector) (ret []*v1beta1.ClusterVaultMap, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.ClusterVaultMap))
+	})
+	return ret, err
+}
+
+// Get retrieves the ClusterVaultMap from the index for a given name.
+func (s *clusterVaultMapLister) Get(name string) (*v1beta1.ClusterVaultMap, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("clustervaultmap"), name)
+	}
+	return obj.(*v1beta1.ClusterVaultMap), nil
+}
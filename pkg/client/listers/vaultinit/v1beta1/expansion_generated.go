@@ -0,0 +1,31 @@
+/*
+Copyright 2017 The Vault Initializer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file was automatically generated by lister-gen
+
+package v1beta1
+
+// ClusterVaultMapListerExpansion allows custom methods to be added to
+// ClusterVaultMapLister.
+type ClusterVaultMapListerExpansion interface{}
+
+// VaultMapListerExpansion allows custom methods to be added to
+// VaultMapLister.
+type VaultMapListerExpansion interface{}
+
+// VaultMapNamespaceListerExpansion allows custom methods to be added to
+// VaultMapNamespaceLister.
+type VaultMapNamespaceListerExpansion interface{}
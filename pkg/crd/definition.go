@@ -0,0 +1,99 @@
+package crd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+)
+
+// ReadSchema returns the validation schema of a version of the custom
+// resource definition in a file. Nil is returned if there isn't one.
+func ReadSchema(path string, version string) (*JSONSchemaProps, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	definition := struct {
+		Spec struct {
+			Version    string            `json:"version"`
+			Validation *validationSchema `json:"validation"`
+			Versions   []struct {
+				Name   string            `json:"name"`
+				Schema *validationSchema `json:"schema"`
+			} `json:"versions"`
+		} `json:"spec"`
+	}{}
+	if err := yaml.Unmarshal(data, &definition); err != nil {
+		return nil, err
+	}
+
+	if len(definition.Spec.Versions) == 0 {
+		if definition.Spec.Version != version || definition.Spec.Validation == nil {
+			return nil, nil
+		}
+		return definition.Spec.Validation.OpenAPIV3Schema, nil
+	}
+	for _, v := range definition.Spec.Versions {
+		if v.Name == version && v.Schema != nil {
+			return v.Schema.OpenAPIV3Schema, nil
+		}
+	}
+	return nil, nil
+}
+
+// WriteSchema sets the validation schemas of the versions of the custom
+// resource definition in a file. A definition with a list of versions gets
+// a schema per version, otherwise the schema of its version is used for
+// the whole definition.
+func WriteSchema(path string, schemas map[string]JSONSchemaProps) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	definition := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &definition); err != nil {
+		return err
+	}
+	spec, ok := definition["spec"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("No spec in custom resource definition %s", path)
+	}
+
+	versions, ok := spec["versions"].([]interface{})
+	if !ok {
+		version, _ := spec["version"].(string)
+		schema, ok := schemas[version]
+		if !ok {
+			return fmt.Errorf("No schema for version %s of custom resource definition %s", version, path)
+		}
+		spec["validation"] = validationSchema{OpenAPIV3Schema: &schema}
+	} else {
+		for _, v := range versions {
+			version, ok := v.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("Invalid version in custom resource definition %s", path)
+			}
+			schema, ok := schemas[fmt.Sprint(version["name"])]
+			if !ok {
+				return fmt.Errorf("No schema for version %v of custom resource definition %s", version["name"], path)
+			}
+			version["schema"] = validationSchema{OpenAPIV3Schema: &schema}
+		}
+		delete(spec, "validation")
+	}
+
+	data, err = yaml.Marshal(definition)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// validationSchema is the validation of a custom resource definition or of
+// one of its versions
+type validationSchema struct {
+	OpenAPIV3Schema *JSONSchemaProps `json:"openAPIV3Schema"`
+}
@@ -0,0 +1,178 @@
+package crd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TemplatePattern matches values whose template actions, such as
+// {{.Namespace}}, are all opened and closed
+const TemplatePattern = `^([^{}]|\{\{[^{}]+\}\})*$`
+
+// JSONSchemaProps is the part of an OpenAPI v3 schema used to validate
+// the vault map custom resources
+type JSONSchemaProps struct {
+	Type       string                     `json:"type,omitempty"`
+	Format     string                     `json:"format,omitempty"`
+	Pattern    string                     `json:"pattern,omitempty"`
+	Enum       []string                   `json:"enum,omitempty"`
+	Required   []string                   `json:"required,omitempty"`
+	Items      *JSONSchemaProps           `json:"items,omitempty"`
+	Properties map[string]JSONSchemaProps `json:"properties,omitempty"`
+	AnyOf      []JSONSchemaProps          `json:"anyOf,omitempty"`
+}
+
+var (
+	timeType = reflect.TypeOf(metav1.Time{})
+
+	// fieldSchemas are the restrictions on fields beyond their type, keyed
+	// by the package and name of the struct and the JSON name of the field
+	fieldSchemas = map[string]JSONSchemaProps{
+		"v1alpha1.MapSpec.vaultPathPattern":       {Pattern: TemplatePattern},
+		"v1alpha1.MapSpec.conflictPolicy":         {Enum: []string{"error", "first", "last"}},
+		"v1alpha1.MapSpec.kvVersion":              {Enum: []string{"1", "2"}},
+		"v1alpha1.MapSpec.secretsPublisher":       {Enum: []string{"env", "volume"}},
+		"v1alpha1.MapSpec.secretsFilePathPattern": {Pattern: TemplatePattern},
+		"v1alpha1.MapSpec.secretsFileNamePattern": {Pattern: TemplatePattern},
+		"v1alpha1.MapSpec.secretNamePattern":      {Pattern: TemplatePattern},
+		"v1alpha1.MapSpec.failurePolicy":          {Enum: []string{"Fail", "Ignore"}},
+		"v1alpha1.VaultPath.pathPattern":          {Pattern: TemplatePattern},
+		"v1alpha1.VaultPath.kvVersion":            {Enum: []string{"1", "2"}},
+		"v1alpha1.VaultPath.secretsPublisher":     {Enum: []string{"env", "volume"}},
+
+		"v1beta1.MapSpec.conflictPolicy":                {Enum: []string{"error", "first", "last"}},
+		"v1beta1.MapSpec.kvVersion":                     {Enum: []string{"1", "2"}},
+		"v1beta1.MapSpec.failurePolicy":                 {Enum: []string{"Fail", "Ignore"}},
+		"v1beta1.VaultPath.path":                        {Pattern: TemplatePattern},
+		"v1beta1.VaultPath.kvVersion":                   {Enum: []string{"1", "2"}},
+		"v1beta1.VaultPath.publisher":                   {Enum: []string{"env", "volume"}},
+		"v1beta1.PublisherSpec.type":                    {Enum: []string{"env", "volume"}},
+		"v1beta1.VolumePublisherSpec.secretNamePattern": {Pattern: TemplatePattern},
+		"v1beta1.VolumePublisherSpec.filePathPattern":   {Pattern: TemplatePattern},
+		"v1beta1.VolumePublisherSpec.fileNamePattern":   {Pattern: TemplatePattern},
+	}
+
+	// structSchemas are the restrictions on structs beyond their fields
+	structSchemas = map[string]JSONSchemaProps{
+		// A map needs at least one vault path
+		"v1alpha1.MapSpec": {AnyOf: []JSONSchemaProps{
+			{Required: []string{"vaultPathPattern"}},
+			{Required: []string{"vaultPaths"}},
+		}},
+	}
+)
+
+// Schemas maps the files of the custom resource definitions in
+// artifacts/crd to the schemas of each version of their resources
+var Schemas = map[string]map[string]func() JSONSchemaProps{
+	"crd.yaml":                      {"v1alpha1": VaultMapSchema},
+	"cluster-crd.yaml":              {"v1alpha1": ClusterVaultMapSchema},
+	"crd-multiversion.yaml":         {"v1alpha1": VaultMapSchema, "v1beta1": VaultMapV1beta1Schema},
+	"cluster-crd-multiversion.yaml": {"v1alpha1": ClusterVaultMapSchema, "v1beta1": ClusterVaultMapV1beta1Schema},
+}
+
+// VaultMapSchema returns the schema of v1alpha1 VaultMap resources
+func VaultMapSchema() JSONSchemaProps {
+	return resourceSchema(v1alpha1.MapSpec{}, v1alpha1.MapStatus{})
+}
+
+// ClusterVaultMapSchema returns the schema of v1alpha1 ClusterVaultMap resources
+func ClusterVaultMapSchema() JSONSchemaProps {
+	return resourceSchema(v1alpha1.MapSpec{}, nil)
+}
+
+// VaultMapV1beta1Schema returns the schema of v1beta1 VaultMap resources
+func VaultMapV1beta1Schema() JSONSchemaProps {
+	return resourceSchema(v1beta1.MapSpec{}, v1beta1.MapStatus{})
+}
+
+// ClusterVaultMapV1beta1Schema returns the schema of v1beta1 ClusterVaultMap resources
+func ClusterVaultMapV1beta1Schema() JSONSchemaProps {
+	return resourceSchema(v1beta1.MapSpec{}, nil)
+}
+
+// resourceSchema returns the schema of a resource with a spec and
+// optionally a status
+func resourceSchema(spec interface{}, status interface{}) JSONSchemaProps {
+	schema := JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]JSONSchemaProps{
+			"spec": typeSchema(reflect.TypeOf(spec)),
+		},
+		Required: []string{"spec"},
+	}
+	if status != nil {
+		schema.Properties["status"] = typeSchema(reflect.TypeOf(status))
+	}
+	return schema
+}
+
+// typeSchema returns the schema of a Go type as it's encoded to JSON
+func typeSchema(t reflect.Type) JSONSchemaProps {
+	if t == timeType {
+		return JSONSchemaProps{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return JSONSchemaProps{Type: "string"}
+	case reflect.Bool:
+		return JSONSchemaProps{Type: "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return JSONSchemaProps{Type: "integer"}
+	case reflect.Slice:
+		items := typeSchema(t.Elem())
+		return JSONSchemaProps{Type: "array", Items: &items}
+	case reflect.Map:
+		return JSONSchemaProps{Type: "object"}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		panic(fmt.Sprintf("Unsupported type %s in schema", t))
+	}
+}
+
+// structSchema returns the schema of a struct. Fields that aren't omitted
+// when empty are required.
+func structSchema(t reflect.Type) JSONSchemaProps {
+	schema := structSchemas[t.String()]
+	schema.Type = "object"
+	schema.Properties = make(map[string]JSONSchemaProps)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		name := tag[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		property := typeSchema(field.Type)
+		if restrictions, ok := fieldSchemas[t.String()+"."+name]; ok {
+			property.Pattern = restrictions.Pattern
+			property.Enum = restrictions.Enum
+		}
+		schema.Properties[name] = property
+
+		omitEmpty := false
+		for _, option := range tag[1:] {
+			if option == "omitempty" {
+				omitEmpty = true
+			}
+		}
+		if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
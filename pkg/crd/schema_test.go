@@ -0,0 +1,112 @@
+package crd
+
+import (
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/inject"
+)
+
+func TestSchemasUpToDate(t *testing.T) {
+	for file, versions := range Schemas {
+		path := filepath.Join("..", "..", "artifacts", "crd", file)
+		for version, schema := range versions {
+			actual, err := ReadSchema(path, version)
+			if err != nil {
+				t.Fatalf("Reading schema from %s resulted in an error: %v", path, err)
+			}
+			if actual == nil || !reflect.DeepEqual(*actual, schema()) {
+				t.Errorf("Schema of %s in %s is out of date with the API types, run go run hack/crd-schema/main.go", version, path)
+			}
+		}
+	}
+}
+
+func TestMapSpecSchema(t *testing.T) {
+	spec := VaultMapSchema().Properties["spec"]
+
+	expected := []string{"secretsPublisher"}
+	if !reflect.DeepEqual(spec.Required, expected) {
+		t.Errorf("Got unexpected required fields: %v", spec.Required)
+	}
+	if enum := spec.Properties["secretsPublisher"].Enum; !reflect.DeepEqual(enum, []string{"env", "volume"}) {
+		t.Errorf("Got unexpected publishers: %v", enum)
+	}
+	if items := spec.Properties["vaultPaths"].Items; items == nil || !reflect.DeepEqual(items.Required, []string{"pathPattern"}) {
+		t.Errorf("Got unexpected vault path schema: %v", items)
+	}
+	if selector := spec.Properties["selector"]; selector.Properties["matchLabels"].Type != "object" || selector.Properties["matchExpressions"].Type != "array" {
+		t.Errorf("Got unexpected selector schema: %v", selector)
+	}
+	if len(spec.AnyOf) != 2 {
+		t.Errorf("Got unexpected vault path requirements: %v", spec.AnyOf)
+	}
+
+	status := VaultMapSchema().Properties["status"]
+	if lastInjection := status.Properties["lastInjectionTime"]; lastInjection.Type != "string" || lastInjection.Format != "date-time" {
+		t.Errorf("Got unexpected last injection time schema: %v", lastInjection)
+	}
+}
+
+func TestMapSpecV1beta1Schema(t *testing.T) {
+	spec := VaultMapV1beta1Schema().Properties["spec"]
+
+	if !reflect.DeepEqual(spec.Required, []string{"paths", "publisher"}) {
+		t.Errorf("Got unexpected required fields: %v", spec.Required)
+	}
+	publisher := spec.Properties["publisher"]
+	if !reflect.DeepEqual(publisher.Properties["type"].Enum, []string{"env", "volume"}) {
+		t.Errorf("Got unexpected publishers: %v", publisher.Properties["type"].Enum)
+	}
+	if pattern := publisher.Properties["volume"].Properties["secretNamePattern"].Pattern; pattern != TemplatePattern {
+		t.Errorf("Got unexpected secret name pattern: %s", pattern)
+	}
+	if _, ok := ClusterVaultMapV1beta1Schema().Properties["status"]; ok {
+		t.Errorf("Expected cluster vault map schema to have no status")
+	}
+}
+
+func TestSchemaPublishers(t *testing.T) {
+	spec := VaultMapSchema().Properties["spec"]
+	for _, publisherType := range spec.Properties["secretsPublisher"].Enum {
+		if _, err := inject.CreatePublisher(publisherType); err != nil {
+			t.Errorf("Creating publisher %s from the schema resulted in an error: %v", publisherType, err)
+		}
+	}
+	if _, err := inject.CreatePublisher("volumes"); err == nil {
+		t.Errorf("Expected an error creating an invalid publisher")
+	}
+}
+
+func TestSchemaConflictPolicies(t *testing.T) {
+	spec := VaultMapSchema().Properties["spec"]
+	for _, policy := range spec.Properties["conflictPolicy"].Enum {
+		if err := inject.MergeSecrets(map[string]string{}, map[string]string{"key": "value"}, "", policy); err != nil {
+			t.Errorf("Merging secrets with policy %s from the schema resulted in an error: %v", policy, err)
+		}
+	}
+}
+
+func TestTemplatePattern(t *testing.T) {
+	pattern := regexp.MustCompile(TemplatePattern)
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"/v1/secret/{{.Namespace}}/{{.ContainerName}}", true},
+		{"config.json", true},
+		{"{{.Namespace}}.{{.ContainerName}}", true},
+		{"", true},
+		{"/v1/secret/{{.Namespace}/app", false},
+		{"/v1/secret/{.Namespace}}", false},
+		{"/v1/secret/{{.Namespace", false},
+	}
+
+	for _, test := range tests {
+		if valid := pattern.MatchString(test.value); valid != test.valid {
+			t.Errorf("Got unexpected validity %t for %s", valid, test.value)
+		}
+	}
+}
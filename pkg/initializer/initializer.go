@@ -6,49 +6,40 @@ import (
 	"time"
 
 	"github.com/golang/glog"
-	vault "github.com/hashicorp/vault/api"
 	clientset "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned"
-	mapscheme "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/scheme"
 	informers "github.com/richardcase/vault-initializer/pkg/client/informers/externalversions"
 	listers "github.com/richardcase/vault-initializer/pkg/client/listers/vaultinit/v1alpha1"
 	"github.com/richardcase/vault-initializer/pkg/inject"
 	"github.com/richardcase/vault-initializer/pkg/model"
-	"k8s.io/api/apps/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/apimachinery/pkg/watch"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/kubernetes/scheme"
-	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
-	appslisters "k8s.io/client-go/listers/apps/v1beta2"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 )
 
-const agentName = "vault-initializer"
-
 // Initializer is the implemntation for the vault initializer
 type Initializer struct {
 	kubeclientset kubernetes.Interface
 	mapclientset  clientset.Interface
 
-	deploymentsLister appslisters.DeploymentLister
-	deploymentsSynced cache.InformerSynced
+	workloads         []workloadResource
+	workloadsSynced   []cache.InformerSynced
 	mapsLister        listers.VaultMapLister
 	mapsSynced        cache.InformerSynced
+	clusterMapsSynced cache.InformerSynced
 
 	namespace       string
-	secrets         map[string]string
 	config          *model.Config
+	injector        *inject.Injector
 	initializerName string
 
 	workqueue workqueue.RateLimitingInterface
@@ -62,61 +53,27 @@ func NewInitializer(
 	kubeInformerFactory kubeinformers.SharedInformerFactory,
 	mapsInformerFactory informers.SharedInformerFactory,
 	namespace string,
-	configmapName string,
-	secretName string,
+	config *model.Config,
+	injector *inject.Injector,
 	initializerName string,
+	recorder record.EventRecorder,
 	stopCh <-chan struct{}) *Initializer {
 
-	config, err := inject.GetInitializerConfig(kubeclientset, namespace, configmapName)
-	if err != nil {
-		glog.Fatal(err)
-	}
-
-	secrets, err := inject.GetInitializerSecret(kubeclientset, namespace, secretName)
-	if err != nil {
-		glog.Fatal(err)
-	}
-
-	//TODO: with the current version (v1.8) this doesn't pick up unitialized deployments
-	// see: https://github.com/kubernetes/kubernetes/pull/51247
-	//deploymentInformer := kubeInformerFactory.Apps().V1beta2().Deployments()
 	mapsInformer := mapsInformerFactory.Vaultinit().V1alpha1().VaultMaps()
-
-	// TODO: Remove this when the above is true
-	restClient := kubeclientset.AppsV1beta1().RESTClient()
-	watchList := cache.NewListWatchFromClient(restClient, "deployments", metav1.NamespaceAll, fields.Everything())
-	includeUninitializwdWatchList := &cache.ListWatch{
-		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
-			options.IncludeUninitialized = true
-			return watchList.List(options)
-		},
-		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
-			options.IncludeUninitialized = true
-			return watchList.Watch(options)
-		},
-	}
-	//**** End Temp Code
-
-	mapscheme.AddToScheme(scheme.Scheme)
-	glog.V(4).Info("Creating event broadcaster")
-	eventBroadcaster := record.NewBroadcaster()
-	eventBroadcaster.StartLogging(glog.Infof)
-	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
-	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: agentName})
+	clusterMapsInformer := mapsInformerFactory.Vaultinit().V1alpha1().ClusterVaultMaps()
 
 	initializer := &Initializer{
-		kubeclientset: kubeclientset,
-		mapclientset:  mapclientset,
-		namespace:     namespace,
-		config:        config,
-		secrets:       secrets,
-		//deploymentsLister: deploymentInformer.Lister(),
-		//deploymentsSynced: deploymentInformer.Informer().HasSynced,
-		deploymentsLister: nil,
+		kubeclientset:     kubeclientset,
+		mapclientset:      mapclientset,
+		namespace:         namespace,
+		config:            config,
+		injector:          injector,
+		workloads:         workloadResources(kubeclientset),
 		mapsLister:        mapsInformer.Lister(),
 		mapsSynced:        mapsInformer.Informer().HasSynced,
+		clusterMapsSynced: clusterMapsInformer.Informer().HasSynced,
 		initializerName:   initializerName,
-		workqueue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "InitDeployments"),
+		workqueue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "InitWorkloads"),
 		recorder:          recorder,
 	}
 
@@ -127,25 +84,37 @@ func NewInitializer(
 		},
 	})
 
-	// Setup event handler for when Deployments resources change
-	//deploymentInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-	_, deploymentInfomer := cache.NewInformer(includeUninitializwdWatchList, &v1beta1.Deployment{}, time.Second*30, cache.ResourceEventHandlerFuncs{
-		AddFunc: initializer.handleObject,
-		UpdateFunc: func(old, new interface{}) {
-			newDepl := new.(*v1beta1.Deployment)
-			oldDepl := old.(*v1beta1.Deployment)
-			if newDepl.ResourceVersion == oldDepl.ResourceVersion {
-				glog.V(2).Infof("Skipping deployment %s as old and new versions are the same %s", newDepl.Name, newDepl.ResourceVersion)
-				return
-			}
-			initializer.handleObject(new)
-		},
-		DeleteFunc: initializer.handleObject,
-	})
+	// Setup event handlers for when workload resources change
+	for idx := range initializer.workloads {
+		workload := &initializer.workloads[idx]
+		kind := workload.kind
+		workloadStore, workloadInformer := cache.NewInformer(workload.uninitializedListWatch(), workload.object, time.Second*30, cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				initializer.handleObject(kind, obj)
+			},
+			UpdateFunc: func(old, new interface{}) {
+				newMeta, err := meta.Accessor(new)
+				if err != nil {
+					utilruntime.HandleError(err)
+					return
+				}
+				oldMeta, err := meta.Accessor(old)
+				if err != nil {
+					utilruntime.HandleError(err)
+					return
+				}
+				if newMeta.GetResourceVersion() == oldMeta.GetResourceVersion() {
+					glog.V(2).Infof("Skipping %s as old and new versions are the same %s", newMeta.GetName(), newMeta.GetResourceVersion())
+					return
+				}
+				initializer.handleObject(kind, new)
+			},
+		})
 
-	//NOTE: These are temporary
-	initializer.setDeploymentCache(deploymentInfomer.HasSynced)
-	go deploymentInfomer.Run(stopCh)
+		workload.store = workloadStore
+		initializer.workloadsSynced = append(initializer.workloadsSynced, workloadInformer.HasSynced)
+		go workloadInformer.Run(stopCh)
+	}
 
 	return initializer
 }
@@ -158,8 +127,8 @@ func (i *Initializer) Run(threadiness int, stopCh <-chan struct{}) error {
 
 	// Wait for the caches to be synced before starting workers
 	glog.Info("Waiting for informer caches to sync")
-	//if ok := cache.WaitForCacheSync(stopCh, i.deploymentsSynced, i.mapsSynced); !ok {
-	if ok := cache.WaitForCacheSync(stopCh, i.mapsSynced); !ok {
+	synced := append([]cache.InformerSynced{i.mapsSynced, i.clusterMapsSynced}, i.workloadsSynced...)
+	if ok := cache.WaitForCacheSync(stopCh, synced...); !ok {
 		return fmt.Errorf("Failed to wait for caches to sync")
 	}
 
@@ -192,22 +161,51 @@ func (i *Initializer) processNextWorkItem() bool {
 	err := func(obj interface{}) error {
 		defer i.workqueue.Done(obj)
 
-		var depl *v1beta1.Deployment
-		var ok bool
-
-		if depl, ok = obj.(*v1beta1.Deployment); !ok {
+		key, ok := obj.(workloadKey)
+		if !ok {
+			i.workqueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("Expected workloadKey in workqueue but got %#v", obj))
+			return nil
+		}
+		resource, ok := i.workloadResource(key.kind)
+		if !ok {
 			i.workqueue.Forget(obj)
-			utilruntime.HandleError(fmt.Errorf("Could cast deployment work item to v1beta1.Deployment"))
+			utilruntime.HandleError(fmt.Errorf("Unsupported workload kind %s", key.kind))
 			return nil
 		}
 
-		if err := i.initializeDeployment(depl); err != nil {
-			i.recorder.Event(depl, corev1.EventTypeWarning, "Error initialising deploymemnt", err.Error())
+		item, exists, err := resource.store.GetByKey(key.key)
+		if err != nil {
+			i.workqueue.Forget(obj)
+			return fmt.Errorf("Error getting %s %s: %v", resource.kind, key.key, err)
+		}
+		if !exists {
+			// The workload has been deleted since it was queued
+			i.workqueue.Forget(obj)
+			glog.V(2).Infof("%s %s no longer exists", resource.kind, key.key)
+			return nil
+		}
+		workload, ok := item.(runtime.Object)
+		if !ok {
+			i.workqueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("Could not cast %s %s to runtime.Object", resource.kind, key.key))
 			return nil
 		}
 
+		if err := i.initializeWorkload(resource, workload); err != nil {
+			i.recorder.Event(workload, corev1.EventTypeWarning, "Error initialising workload", err.Error())
+			if i.workqueue.NumRequeues(obj) < i.config.MaxRetries {
+				i.workqueue.AddRateLimited(obj)
+				return fmt.Errorf("Error initializing %s, requeuing: %v", resource.kind, err)
+			}
+
+			retries := i.workqueue.NumRequeues(obj)
+			i.workqueue.Forget(obj)
+			return i.abandonWorkload(resource, workload, retries, err)
+		}
+
 		i.workqueue.Forget(obj)
-		glog.Info("Successfully initialized deployment %s", depl.Name)
+		glog.V(2).Infof("Successfully processed %s", resource.kind)
 		return nil
 	}(obj)
 
@@ -220,145 +218,145 @@ func (i *Initializer) processNextWorkItem() bool {
 	return true
 }
 
-func (i *Initializer) handleObject(obj interface{}) {
+// handleObject queues a workload by its kind and namespace/name key. Only
+// retries are rate limited, so the requeue count of a workload is the number
+// of times it has been retried.
+func (i *Initializer) handleObject(kind string, obj interface{}) {
 	glog.V(2).Info("In handle object")
-	i.workqueue.AddRateLimited(obj)
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	i.workqueue.Add(workloadKey{kind: kind, key: key})
 }
 
-func (i *Initializer) initializeDeployment(deployment *v1beta1.Deployment) error {
-
-	//TODO: Move this else where
-	vaultConfig := vault.DefaultConfig()
-	if i.config.VaultAddress != "" {
-		vaultConfig.Address = i.config.VaultAddress
+// workloadResource returns the watched workload resource of a kind
+func (i *Initializer) workloadResource(kind string) (workloadResource, bool) {
+	for _, resource := range i.workloads {
+		if resource.kind == kind {
+			return resource, true
+		}
 	}
-	vaultClient, err := vault.NewClient(vaultConfig)
+	return workloadResource{}, false
+}
+
+func (i *Initializer) initializeWorkload(resource workloadResource, obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
 	if err != nil {
-		glog.Fatal(err.Error())
+		return err
 	}
+	if !i.isPending(accessor) {
+		return nil
+	}
+	glog.Infof("Initializing %s: %s", resource.kind, accessor.GetName())
 
-	if deployment.ObjectMeta.GetInitializers() != nil {
-		pendingInitializers := deployment.ObjectMeta.GetInitializers().Pending
-
-		if i.initializerName == pendingInitializers[0].Name {
-			glog.Infof("Initializing deployment: %s", deployment.Name)
-
-			o, err := runtime.NewScheme().DeepCopy(deployment)
-			if err != nil {
-				return err
-			}
-			initializedDeployment := o.(*v1beta1.Deployment)
-
-			// Remove self from the list of pending Initializers while preserving ordering.
-			if len(pendingInitializers) == 1 {
-				initializedDeployment.ObjectMeta.Initializers = nil
-			} else {
-				initializedDeployment.ObjectMeta.Initializers.Pending = append(pendingInitializers[:0], pendingInitializers[1:]...)
-			}
+	initializedObj, err := removeInitializer(obj)
+	if err != nil {
+		return err
+	}
 
-			if i.config.IgnoreSystemNamespaces && deployment.Namespace == "kube-system" {
-				glog.Infof("Ignoring deployments in kube-system namespace")
-				_, err = i.kubeclientset.AppsV1beta1().Deployments(deployment.Namespace).Update(initializedDeployment)
-				return err
-			}
+	workload, err := inject.NewWorkload(initializedObj)
+	if err != nil {
+		return err
+	}
+	injected, err := i.injector.Inject(workload)
+	if err != nil {
+		return err
+	}
+	if !injected {
+		return updateWorkload(resource, accessor, initializedObj)
+	}
 
-			if i.config.RequireAnnotation {
-				a := deployment.ObjectMeta.GetAnnotations()
-				_, ok := a[i.config.AnnotatioName]
-				if !ok {
-					glog.V(2).Infof("Required '%s' annotation missing; skipping vault injection", i.config.AnnotatioName)
-					_, err = i.kubeclientset.AppsV1beta1().Deployments(deployment.Namespace).Update(initializedDeployment)
-					return err
-				}
-			}
+	oldData, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
 
-			maps, err := i.mapsLister.VaultMaps(initializedDeployment.Namespace).List(labels.NewSelector())
-			if err != nil {
-				return err
-			}
-			if len(maps) == 0 {
-				glog.V(2).Infof("No VaultMap for namespace %s; skipping vault injection", initializedDeployment.Namespace)
-				_, err = i.kubeclientset.AppsV1beta1().Deployments(deployment.Namespace).Update(initializedDeployment)
-				return err
-			}
-			vaultmap := maps[0]
+	newData, err := json.Marshal(initializedObj)
+	if err != nil {
+		return err
+	}
 
-			vaultPath, err := inject.ResolveTemplate(initializedDeployment, vaultmap.Spec.VaultPathPattern)
-			if err != nil {
-				return err
-			}
-			glog.V(2).Infof("Querying vault with path: %s", vaultPath)
-			request := vaultClient.NewRequest("GET", vaultPath)
-			if i.config.VaultAuthMode == "Token" {
-				request.ClientToken = i.secrets["vaultToken"]
-			}
-			resp, err := vaultClient.RawRequest(request)
-			if err != nil {
-				glog.Errorf("Error querying vault for secrets for %s: %v", vaultPath, err.Error())
-				return err
-			}
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, resource.object)
+	if err != nil {
+		return err
+	}
 
-			defer func() {
-				if resp != nil && resp.Body != nil {
-					_ = resp.Body.Close()
-				}
-			}()
+	err = resource.client.Patch(types.StrategicMergePatchType).
+		Namespace(accessor.GetNamespace()).
+		Resource(resource.resource).
+		Name(accessor.GetName()).
+		Body(patchBytes).
+		Do().
+		Error()
+	if err != nil {
+		return err
+	}
+	glog.Infof("Patched %s: %s\n", resource.kind, accessor.GetName())
+	return nil
+}
 
-			if resp != nil && resp.StatusCode == 404 {
-				glog.Infof("No secrets in vault for path %s", vaultPath)
-				_, err = i.kubeclientset.AppsV1beta1().Deployments(deployment.Namespace).Update(initializedDeployment)
-				return err
-			}
-			secret, err := vault.ParseSecret(resp.Body)
-			if err != nil {
-				return err
-			}
-			secrets := make(map[string]string)
-			for key, value := range secret.Data {
-				i.secrets[key] = value.(string)
-			}
-			publisher, err := inject.CreatePublisher(vaultmap.Spec.SecretsPublisher)
-			if err != nil {
-				return err
-			}
-			err = publisher.PublishSecrets(vaultmap, i.kubeclientset.(*kubernetes.Clientset), initializedDeployment, secrets)
-			if err != nil {
-				return err
-			}
+// abandonWorkload gives up on a workload that couldn't be initialized after
+// retrying. With the Ignore failure policy, from the vault map or the config,
+// the initializer is removed so the workload is created without secrets,
+// otherwise it's left uninitialized.
+func (i *Initializer) abandonWorkload(resource workloadResource, obj runtime.Object, retries int, err error) error {
+	accessor, accessorErr := meta.Accessor(obj)
+	if accessorErr != nil {
+		return accessorErr
+	}
+	if !i.isPending(accessor) {
+		return nil
+	}
 
-			oldData, err := json.Marshal(deployment)
-			if err != nil {
-				return err
-			}
+	if i.injector.FailurePolicy(err) != model.FailurePolicyIgnore {
+		glog.Errorf("Giving up initializing %s %s after %d retries, leaving it uninitialized: %v", resource.kind, accessor.GetName(), retries, err)
+		i.recorder.Eventf(obj, corev1.EventTypeWarning, "InitializationFailed", "Giving up injecting secrets after %d retries, leaving %s uninitialized: %v", retries, resource.kind, err)
+		return nil
+	}
 
-			// Flag that this container has vault secrets
-			if initializedDeployment.Spec.Template.Annotations == nil {
-				annotations := make(map[string]string)
-				initializedDeployment.Spec.Template.SetAnnotations(annotations)
-			}
-			initializedDeployment.Spec.Template.Annotations["vault-secrets-initialized"] = "true"
+	glog.Errorf("Giving up initializing %s %s after %d retries, creating it without secrets: %v", resource.kind, accessor.GetName(), retries, err)
+	i.recorder.Eventf(obj, corev1.EventTypeWarning, "InitializationSkipped", "Giving up injecting secrets after %d retries, creating %s without secrets: %v", retries, resource.kind, err)
+	initializedObj, err := removeInitializer(obj)
+	if err != nil {
+		return err
+	}
+	return updateWorkload(resource, accessor, initializedObj)
+}
 
-			newData, err := json.Marshal(initializedDeployment)
-			if err != nil {
-				return err
-			}
+// isPending returns true if this initializer is the next pending
+// initializer of an object
+func (i *Initializer) isPending(accessor metav1.Object) bool {
+	initializers := accessor.GetInitializers()
+	return initializers != nil && len(initializers.Pending) > 0 && initializers.Pending[0].Name == i.initializerName
+}
 
-			patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, v1beta1.Deployment{})
-			if err != nil {
-				return err
-			}
+// removeInitializer returns a copy of an object with the first pending
+// initializer removed, preserving the order of the others
+func removeInitializer(obj runtime.Object) (runtime.Object, error) {
+	initializedObj := obj.DeepCopyObject()
+	accessor, err := meta.Accessor(initializedObj)
+	if err != nil {
+		return nil, err
+	}
 
-			_, err = i.kubeclientset.AppsV1beta1().Deployments(deployment.Namespace).Patch(deployment.Name, types.StrategicMergePatchType, patchBytes)
-			if err != nil {
-				return err
-			}
-			glog.Infof("Patched Deployment: %s\n", deployment.Name)
-		}
+	if len(accessor.GetInitializers().Pending) == 1 {
+		accessor.SetInitializers(nil)
+	} else {
+		initializers := accessor.GetInitializers()
+		initializers.Pending = initializers.Pending[1:]
 	}
-	return nil
+	return initializedObj, nil
 }
 
-// NOTE: This is a function only until the deployment informer supports unitiilaized
-func (i *Initializer) setDeploymentCache(synced cache.InformerSynced) {
-	i.deploymentsSynced = synced
+// updateWorkload replaces a workload
+func updateWorkload(resource workloadResource, accessor metav1.Object, obj runtime.Object) error {
+	return resource.client.Put().
+		Namespace(accessor.GetNamespace()).
+		Resource(resource.resource).
+		Name(accessor.GetName()).
+		Body(obj).
+		Do().
+		Error()
 }
@@ -0,0 +1,245 @@
+package initializer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mapfake "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/fake"
+	listers "github.com/richardcase/vault-initializer/pkg/client/listers/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/inject"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	"k8s.io/api/apps/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const testInitializerName = "vault.initializer.kubernetes.io"
+
+// testDeployment returns a deployment pending the initializer that names a
+// vault map that doesn't exist, so injecting secrets into it fails
+func testDeployment() *v1beta1.Deployment {
+	return &v1beta1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    "default",
+			Name:         "payments",
+			Annotations:  map[string]string{inject.MapAnnotation: "missing"},
+			Initializers: &metav1.Initializers{Pending: []metav1.Initializer{{Name: testInitializerName}}},
+		},
+		Spec: v1beta1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "payments", Image: "payments:1.0"}}},
+			},
+		},
+	}
+}
+
+// testInitializer returns an initializer watching deployments, which are
+// updated through the client, with the workloads in its cache. Retries
+// aren't delayed.
+func testInitializer(config *model.Config, client rest.Interface, workloads ...runtime.Object) (*Initializer, *record.FakeRecorder) {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for _, workload := range workloads {
+		store.Add(workload)
+	}
+	mapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	clusterMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	recorder := record.NewFakeRecorder(20)
+
+	return &Initializer{
+		workloads:       []workloadResource{{kind: "Deployment", resource: "deployments", client: client, object: &v1beta1.Deployment{}, store: store}},
+		config:          config,
+		injector:        inject.NewInjector(nil, mapfake.NewSimpleClientset(), listers.NewVaultMapLister(mapIndexer), listers.NewClusterVaultMapLister(clusterMapIndexer), nil, nil, config, recorder),
+		initializerName: testInitializerName,
+		workqueue:       workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(0, 0), "InitWorkloads"),
+		recorder:        recorder,
+	}, recorder
+}
+
+// testRESTClient returns a client for a server that records the requests
+// made to it
+func testRESTClient(t *testing.T) (rest.Interface, *[]*http.Request, *[][]byte, func()) {
+	var requests []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+
+	client, err := rest.RESTClientFor(&rest.Config{
+		Host:    server.URL,
+		APIPath: "/apis",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion:         &v1beta1.SchemeGroupVersion,
+			NegotiatedSerializer: scheme.Codecs,
+		},
+	})
+	if err != nil {
+		server.Close()
+		t.Fatalf("Creating REST client resulted in an error: %v", err)
+	}
+	return client, &requests, &bodies, server.Close
+}
+
+// events returns the events recorded so far
+func events(recorder *record.FakeRecorder) []string {
+	var recorded []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			recorded = append(recorded, event)
+		default:
+			return recorded
+		}
+	}
+}
+
+func TestHandleObjectQueuesKey(t *testing.T) {
+	deployment := testDeployment()
+	initializer, _ := testInitializer(&model.Config{}, nil, deployment)
+
+	initializer.handleObject("Deployment", deployment)
+	if initializer.workqueue.Len() != 1 {
+		t.Fatalf("Got unexpected queue length: %d, expected 1", initializer.workqueue.Len())
+	}
+	key, _ := initializer.workqueue.Get()
+	if expected := (workloadKey{kind: "Deployment", key: "default/payments"}); key != expected {
+		t.Errorf("Got unexpected queue key: %v, expected %v", key, expected)
+	}
+	if requeues := initializer.workqueue.NumRequeues(key); requeues != 0 {
+		t.Errorf("Got unexpected requeues for a new workload: %d, expected 0", requeues)
+	}
+}
+
+func TestProcessNextWorkItemRequeues(t *testing.T) {
+	deployment := testDeployment()
+	initializer, recorder := testInitializer(&model.Config{MaxRetries: 2, FailurePolicy: model.FailurePolicyFail}, nil, deployment)
+	initializer.handleObject("Deployment", deployment)
+	key := workloadKey{kind: "Deployment", key: "default/payments"}
+
+	for retries := 1; retries <= 2; retries++ {
+		if !initializer.processNextWorkItem() {
+			t.Fatalf("Expected the workqueue to still be running")
+		}
+		if requeues := initializer.workqueue.NumRequeues(key); requeues != retries {
+			t.Errorf("Got unexpected requeues: %d, expected %d", requeues, retries)
+		}
+		if initializer.workqueue.Len() != 1 {
+			t.Errorf("Got unexpected queue length: %d, expected the workload to be requeued", initializer.workqueue.Len())
+		}
+	}
+
+	for _, event := range events(recorder) {
+		if strings.Contains(event, "InitializationFailed") {
+			t.Errorf("Got unexpected event before reaching the retry limit: %s", event)
+		}
+	}
+}
+
+func TestProcessNextWorkItemGivesUp(t *testing.T) {
+	deployment := testDeployment()
+	initializer, recorder := testInitializer(&model.Config{MaxRetries: 2, FailurePolicy: model.FailurePolicyFail}, nil, deployment)
+	initializer.handleObject("Deployment", deployment)
+	key := workloadKey{kind: "Deployment", key: "default/payments"}
+
+	attempts := 0
+	for initializer.workqueue.Len() > 0 && attempts < 5 {
+		initializer.processNextWorkItem()
+		attempts++
+	}
+	if attempts != 3 {
+		t.Errorf("Got unexpected attempts: %d, expected the first attempt and 2 retries", attempts)
+	}
+	if requeues := initializer.workqueue.NumRequeues(key); requeues != 0 {
+		t.Errorf("Got unexpected requeues after giving up: %d, expected the workload to be forgotten", requeues)
+	}
+
+	recorded := events(recorder)
+	if len(recorded) == 0 {
+		t.Fatalf("Expected events for the failed attempts")
+	}
+	last := recorded[len(recorded)-1]
+	if !strings.Contains(last, "InitializationFailed") || !strings.Contains(last, "after 2 retries") {
+		t.Errorf("Got unexpected event: %s, expected InitializationFailed after 2 retries", last)
+	}
+}
+
+func TestProcessNextWorkItemDeleted(t *testing.T) {
+	initializer, recorder := testInitializer(&model.Config{MaxRetries: 2}, nil)
+	initializer.handleObject("Deployment", testDeployment())
+
+	initializer.processNextWorkItem()
+	if initializer.workqueue.Len() != 0 {
+		t.Errorf("Got unexpected queue length: %d, expected a deleted workload to be dropped", initializer.workqueue.Len())
+	}
+	if recorded := events(recorder); len(recorded) != 0 {
+		t.Errorf("Got unexpected events for a deleted workload: %v", recorded)
+	}
+}
+
+func TestAbandonWorkloadFail(t *testing.T) {
+	client, requests, _, closeServer := testRESTClient(t)
+	defer closeServer()
+	deployment := testDeployment()
+	initializer, recorder := testInitializer(&model.Config{FailurePolicy: model.FailurePolicyFail}, client, deployment)
+
+	err := initializer.abandonWorkload(initializer.workloads[0], deployment, 5, &inject.InjectionError{Err: errors.New("Vault sealed")})
+	if err != nil {
+		t.Fatalf("Abandoning workload resulted in an error: %v", err)
+	}
+	if len(*requests) != 0 {
+		t.Errorf("Got unexpected requests: %d, expected the workload to be left uninitialized", len(*requests))
+	}
+	recorded := events(recorder)
+	if len(recorded) != 1 || !strings.Contains(recorded[0], "InitializationFailed") || !strings.Contains(recorded[0], "after 5 retries") {
+		t.Errorf("Got unexpected events: %v, expected InitializationFailed after 5 retries", recorded)
+	}
+}
+
+func TestAbandonWorkloadIgnore(t *testing.T) {
+	client, requests, bodies, closeServer := testRESTClient(t)
+	defer closeServer()
+	deployment := testDeployment()
+	// The failure policy of the vault map takes precedence over the config
+	initializer, recorder := testInitializer(&model.Config{FailurePolicy: model.FailurePolicyFail}, client, deployment)
+
+	err := initializer.abandonWorkload(initializer.workloads[0], deployment, 5, &inject.InjectionError{Err: errors.New("Vault sealed"), FailurePolicy: model.FailurePolicyIgnore})
+	if err != nil {
+		t.Fatalf("Abandoning workload resulted in an error: %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("Got unexpected requests: %d, expected the workload to be updated", len(*requests))
+	}
+	request := (*requests)[0]
+	if request.Method != http.MethodPut || request.URL.Path != "/apis/apps/v1beta1/namespaces/default/deployments/payments" {
+		t.Errorf("Got unexpected request: %s %s", request.Method, request.URL.Path)
+	}
+	var updated v1beta1.Deployment
+	if err := json.Unmarshal((*bodies)[0], &updated); err != nil {
+		t.Fatalf("Decoding updated deployment resulted in an error: %v", err)
+	}
+	if updated.Initializers != nil {
+		t.Errorf("Got unexpected initializers: %v, expected the initializer to be removed", updated.Initializers)
+	}
+	if deployment.Initializers == nil {
+		t.Errorf("Expected the cached deployment to be left unchanged")
+	}
+
+	recorded := events(recorder)
+	if len(recorded) != 1 || !strings.Contains(recorded[0], "InitializationSkipped") || !strings.Contains(recorded[0], "after 5 retries") {
+		t.Errorf("Got unexpected events: %v, expected InitializationSkipped after 5 retries", recorded)
+	}
+}
@@ -0,0 +1,57 @@
+package initializer
+
+import (
+	"k8s.io/api/apps/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+// workloadResource is a kind of workload that the initializer watches
+type workloadResource struct {
+	kind     string
+	resource string
+	client   rest.Interface
+	object   runtime.Object
+	// store is the cache of the informer watching the resource
+	store cache.Store
+}
+
+// workloadKey is the work queue key of a workload, the kind of its resource
+// and its namespace/name key
+type workloadKey struct {
+	kind string
+	key  string
+}
+
+func workloadResources(kubeclientset kubernetes.Interface) []workloadResource {
+	return []workloadResource{
+		{kind: "Deployment", resource: "deployments", client: kubeclientset.AppsV1beta1().RESTClient(), object: &v1beta1.Deployment{}},
+		{kind: "StatefulSet", resource: "statefulsets", client: kubeclientset.AppsV1beta1().RESTClient(), object: &v1beta1.StatefulSet{}},
+		{kind: "DaemonSet", resource: "daemonsets", client: kubeclientset.ExtensionsV1beta1().RESTClient(), object: &extensionsv1beta1.DaemonSet{}},
+		{kind: "Job", resource: "jobs", client: kubeclientset.BatchV1().RESTClient(), object: &batchv1.Job{}},
+	}
+}
+
+// uninitializedListWatch returns a list watch that includes uninitialized
+// objects. The shared informers don't pick them up with the current version (v1.8),
+// see: https://github.com/kubernetes/kubernetes/pull/51247
+func (r workloadResource) uninitializedListWatch() *cache.ListWatch {
+	watchList := cache.NewListWatchFromClient(r.client, r.resource, metav1.NamespaceAll, fields.Everything())
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.IncludeUninitialized = true
+			return watchList.List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.IncludeUninitialized = true
+			return watchList.Watch(options)
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package inject
 
 import (
+	"fmt"
+
 	"github.com/golang/glog"
 	"github.com/richardcase/vault-initializer/pkg/model"
 	yaml "gopkg.in/yaml.v2"
@@ -11,6 +13,9 @@ import (
 
 const (
 	defaultAnnotation = "initializer.kubernetes.io/vault"
+	defaultMaxRetries = 5
+	// defaultVaultMaxRetries is the default of the vault client
+	defaultVaultMaxRetries = 2
 )
 
 // GetInitializerConfig gets the initializer configuration from a Kubernetes configmap
@@ -29,12 +34,27 @@ func GetInitializerConfig(client clientset.Interface, namespace, configName stri
 	if config.AnnotatioName == "" {
 		config.AnnotatioName = defaultAnnotation
 	}
+	if config.MaxRetries < 0 {
+		return nil, fmt.Errorf("Invalid Max Retries: %d", config.MaxRetries)
+	}
+	if config.VaultMaxRetries < 0 {
+		return nil, fmt.Errorf("Invalid Vault Max Retries: %d", config.VaultMaxRetries)
+	}
+	switch config.FailurePolicy {
+	case "":
+		config.FailurePolicy = model.FailurePolicyFail
+	case model.FailurePolicyFail, model.FailurePolicyIgnore:
+	default:
+		return nil, fmt.Errorf("Invalid Failure Policy: %s", config.FailurePolicy)
+	}
 
 	return config, nil
 }
 
+// configmapToConfig reads the config from a configmap. Defaults that can be
+// set to zero are set before reading it so they're only used for unset keys.
 func configmapToConfig(configmap *corev1.ConfigMap) (*model.Config, error) {
-	var c model.Config
+	c := model.Config{MaxRetries: defaultMaxRetries, VaultMaxRetries: defaultVaultMaxRetries}
 	err := yaml.Unmarshal([]byte(configmap.Data["config"]), &c)
 	if err != nil {
 		return nil, err
@@ -87,6 +87,69 @@ func TestGetVaultConfigMapWithDefaultAnnotation(t *testing.T) {
 	if config.AnnotatioName != "initializer.kubernetes.io/vault" {
 		t.Errorf("Got unexpected AnnotationName: %s", config.AnnotatioName)
 	}
+	if config.MaxRetries != 5 {
+		t.Errorf("Got unexpected MaxRetries: %d", config.MaxRetries)
+	}
+	if config.VaultMaxRetries != 2 {
+		t.Errorf("Got unexpected VaultMaxRetries: %d", config.VaultMaxRetries)
+	}
+	if config.FailurePolicy != "Fail" {
+		t.Errorf("Got unexpected FailurePolicy: %s", config.FailurePolicy)
+	}
+}
+
+func TestGetVaultConfigMapWithFailurePolicy(t *testing.T) {
+	cm := configMap("default", "vault-initializer", initConfig+"    maxRetries: 2\n    failurePolicy: Ignore\n")
+	fakeClient := fake.NewSimpleClientset(&cm)
+
+	config, err := GetInitializerConfig(fakeClient, "default", "vault-initializer")
+	if err != nil {
+		t.Fatalf("Getting config resulted in an error: %v.", err)
+	}
+
+	if config.MaxRetries != 2 {
+		t.Errorf("Got unexpected MaxRetries: %d", config.MaxRetries)
+	}
+	if config.FailurePolicy != "Ignore" {
+		t.Errorf("Got unexpected FailurePolicy: %s", config.FailurePolicy)
+	}
+}
+
+func TestGetVaultConfigMapWithoutRetries(t *testing.T) {
+	cm := configMap("default", "vault-initializer", initConfig+"    maxRetries: 0\n    vaultMaxRetries: 0\n")
+	fakeClient := fake.NewSimpleClientset(&cm)
+
+	config, err := GetInitializerConfig(fakeClient, "default", "vault-initializer")
+	if err != nil {
+		t.Fatalf("Getting config resulted in an error: %v.", err)
+	}
+
+	if config.MaxRetries != 0 {
+		t.Errorf("Got unexpected MaxRetries: %d", config.MaxRetries)
+	}
+	if config.VaultMaxRetries != 0 {
+		t.Errorf("Got unexpected VaultMaxRetries: %d", config.VaultMaxRetries)
+	}
+}
+
+func TestGetVaultConfigMapInvalidMaxRetries(t *testing.T) {
+	for _, option := range []string{"maxRetries", "vaultMaxRetries"} {
+		cm := configMap("default", "vault-initializer", initConfig+"    "+option+": -1\n")
+		fakeClient := fake.NewSimpleClientset(&cm)
+
+		if _, err := GetInitializerConfig(fakeClient, "default", "vault-initializer"); err == nil {
+			t.Errorf("Getting config with negative %s resulted in no error where an error was expected", option)
+		}
+	}
+}
+
+func TestGetVaultConfigMapInvalidFailurePolicy(t *testing.T) {
+	cm := configMap("default", "vault-initializer", initConfig+"    failurePolicy: Open\n")
+	fakeClient := fake.NewSimpleClientset(&cm)
+
+	if _, err := GetInitializerConfig(fakeClient, "default", "vault-initializer"); err == nil {
+		t.Error("Getting config with an invalid failure policy resulted in no error where an error was expected")
+	}
 }
 
 func TestMissingVaultConfigMap(t *testing.T) {
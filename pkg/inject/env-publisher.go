@@ -2,7 +2,7 @@ package inject
 
 import (
 	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
-	"k8s.io/api/apps/v1beta1"
+	"github.com/richardcase/vault-initializer/pkg/model"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -11,10 +11,10 @@ import (
 type EnvironmentPublisher struct{}
 
 // PublishSecrets publishes secrets as environment variables.
-func (p EnvironmentPublisher) PublishSecrets(vaultmap *v1alpha1.VaultMap, clientset *kubernetes.Clientset, deployment *v1beta1.Deployment, secrets map[string]string) error {
+func (p EnvironmentPublisher) PublishSecrets(vaultmap *v1alpha1.VaultMap, clientset kubernetes.Interface, workload *model.Workload, container *corev1.Container, secrets map[string]string) error {
 	for key, value := range secrets {
 		env := corev1.EnvVar{Name: key, Value: value}
-		deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, env)
+		container.Env = append(container.Env, env)
 	}
 
 	return nil
@@ -0,0 +1,57 @@
+package inject
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestEnvironmentPublisherPerContainer(t *testing.T) {
+	deployment := testDeployment(nil)
+	containers := &deployment.Spec.Template.Spec.Containers
+	*containers = append(*containers, corev1.Container{Name: "sidecar"})
+	workload := DeploymentWorkload(deployment)
+
+	publisher := EnvironmentPublisher{}
+	if err := publisher.PublishSecrets(testVaultMap("1"), nil, workload, &(*containers)[0], map[string]string{"mysecret": "Password123"}); err != nil {
+		t.Fatalf("Publishing secrets resulted in an error: %v", err)
+	}
+	if err := publisher.PublishSecrets(testVaultMap("1"), nil, workload, &(*containers)[1], map[string]string{"othersecret": "Password456"}); err != nil {
+		t.Fatalf("Publishing secrets resulted in an error: %v", err)
+	}
+
+	assertEnv(t, &(*containers)[0], map[string]string{"mysecret": "Password123"})
+	assertEnv(t, &(*containers)[1], map[string]string{"othersecret": "Password456"})
+	if len(workload.Template.Spec.Volumes) != 0 {
+		t.Errorf("Got unexpected volumes: %v", workload.Template.Spec.Volumes)
+	}
+}
+
+func TestEnvironmentPublisherInitContainers(t *testing.T) {
+	for _, initContainers := range []bool{true, false} {
+		vaultmap := testVaultMap("1")
+		vaultmap.Spec.InjectInitContainers = initContainers
+		deployment := testDeployment(nil)
+		deployment.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "migrate"}}
+
+		publishToTargets(t, EnvironmentPublisher{}, vaultmap, nil, DeploymentWorkload(deployment), map[string]string{"mysecret": "Password123"})
+
+		assertEnv(t, &deployment.Spec.Template.Spec.Containers[0], map[string]string{"mysecret": "Password123"})
+		if initContainers {
+			assertEnv(t, &deployment.Spec.Template.Spec.InitContainers[0], map[string]string{"mysecret": "Password123"})
+		} else {
+			assertEnv(t, &deployment.Spec.Template.Spec.InitContainers[0], map[string]string{})
+		}
+	}
+}
+
+func assertEnv(t *testing.T, container *corev1.Container, expected map[string]string) {
+	if len(container.Env) != len(expected) {
+		t.Errorf("Got unexpected env vars in container %s: %v", container.Name, container.Env)
+	}
+	for _, env := range container.Env {
+		if value, ok := expected[env.Name]; !ok || env.Value != value {
+			t.Errorf("Got unexpected env var in container %s: %s=%s", container.Name, env.Name, env.Value)
+		}
+	}
+}
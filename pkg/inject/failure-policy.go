@@ -0,0 +1,22 @@
+package inject
+
+// InjectionError is an error injecting secrets into a workload using a
+// vault map, along with the failure policy of the vault map
+type InjectionError struct {
+	Err           error
+	FailurePolicy string
+}
+
+func (e *InjectionError) Error() string {
+	return e.Err.Error()
+}
+
+// FailurePolicy returns the failure policy for an error returned by Inject.
+// The failure policy of the vault map takes precedence over the one in the
+// config.
+func (in *Injector) FailurePolicy(err error) string {
+	if injectErr, ok := err.(*InjectionError); ok && injectErr.FailurePolicy != "" {
+		return injectErr.FailurePolicy
+	}
+	return in.config.FailurePolicy
+}
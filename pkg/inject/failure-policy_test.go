@@ -0,0 +1,27 @@
+package inject
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/model"
+)
+
+func TestFailurePolicy(t *testing.T) {
+	injector, _ := testInjector(nil, nil)
+	injector.config.FailurePolicy = model.FailurePolicyFail
+
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{errors.New("an error"), model.FailurePolicyFail},
+		{&InjectionError{Err: errors.New("an error")}, model.FailurePolicyFail},
+		{&InjectionError{Err: errors.New("an error"), FailurePolicy: model.FailurePolicyIgnore}, model.FailurePolicyIgnore},
+	}
+	for _, test := range tests {
+		if policy := injector.FailurePolicy(test.err); policy != test.expected {
+			t.Errorf("Got unexpected failure policy for %#v: %s", test.err, policy)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package inject
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/golang/glog"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	"github.com/richardcase/vault-initializer/pkg/vaultclient"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// VersionAnnotation pins the version of a KV v2 secret used for a workload
+const VersionAnnotation = "vault.initializer/version"
+
+// FetchedSecrets are the secrets read from vault for a container
+type FetchedSecrets struct {
+	// Values are the merged secrets of all the paths, keyed by the type of
+	// publisher that publishes them
+	Values map[string]map[string]string
+	// Version is the version of the secret read from the first path, which
+	// is the path the version annotation pins. It is zero for KV v1.
+	Version int
+}
+
+// Publishers returns the types of publisher that secrets were fetched for, sorted by name
+func (f *FetchedSecrets) Publishers() []string {
+	publishers := make([]string, 0, len(f.Values))
+	for publisher := range f.Values {
+		publishers = append(publishers, publisher)
+	}
+	sort.Strings(publishers)
+	return publishers
+}
+
+// VaultPaths returns the vault paths of a vault map with the defaults from
+// the spec applied. The path pattern of the spec is the first path if it's set.
+func VaultPaths(vaultmap *v1alpha1.VaultMap) []v1alpha1.VaultPath {
+	var paths []v1alpha1.VaultPath
+	if vaultmap.Spec.VaultPathPattern != "" {
+		paths = append(paths, v1alpha1.VaultPath{PathPattern: vaultmap.Spec.VaultPathPattern})
+	}
+	paths = append(paths, vaultmap.Spec.VaultPaths...)
+
+	for i := range paths {
+		if paths[i].KVVersion == "" {
+			paths[i].KVVersion = vaultmap.Spec.KVVersion
+		}
+		if paths[i].SecretsPublisher == "" {
+			paths[i].SecretsPublisher = vaultmap.Spec.SecretsPublisher
+		}
+	}
+	return paths
+}
+
+// FetchSecrets reads the secrets for a container of a workload from the vault
+// paths of the vault map. The secrets of the paths are merged using the
+// conflict policy of the vault map. A version pinned by the version annotation
+// only applies to the first path, as versions are per secret, and the other
+// paths are read at their latest version. Neither the workload nor the vault
+// map are modified. Nil is returned if vault has no secrets for the container.
+func FetchSecrets(client *vault.Client, token string, vaultmap *v1alpha1.VaultMap, workload *model.Workload, container *corev1.Container) (*FetchedSecrets, error) {
+	var err error
+	version := 0
+	if v, ok := workload.Annotations[VersionAnnotation]; ok {
+		version, err = strconv.Atoi(v)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("Invalid secret version '%s' in %s annotation", v, VersionAnnotation)
+		}
+	}
+
+	var fetched *FetchedSecrets
+	for i, path := range VaultPaths(vaultmap) {
+		vaultPath, err := ResolveTemplate(workload, container, path.PathPattern)
+		if err != nil {
+			return nil, err
+		}
+
+		glog.V(2).Infof("Querying vault with path: %s", vaultPath)
+		pinned := 0
+		if i == 0 {
+			pinned = version
+		}
+		secret, err := vaultclient.ReadKV(client, token, vaultPath, path.KVVersion, pinned)
+		if err != nil {
+			glog.Errorf("Error querying vault for secrets for %s: %v", vaultPath, err.Error())
+			return nil, err
+		}
+		if secret == nil && pinned > 0 {
+			return nil, fmt.Errorf("No version %d of the secret in vault for path %s", pinned, vaultPath)
+		}
+		if secret == nil {
+			glog.Infof("No secrets in vault for path %s", vaultPath)
+			continue
+		}
+
+		values, err := ConvertSecrets(secret.Data, vaultmap.Spec.FlattenSecrets)
+		if err != nil {
+			return nil, err
+		}
+
+		if fetched == nil {
+			fetched = &FetchedSecrets{Values: make(map[string]map[string]string)}
+		}
+		if i == 0 {
+			fetched.Version = secret.Version
+		}
+		merged, ok := fetched.Values[path.SecretsPublisher]
+		if !ok {
+			merged = make(map[string]string)
+			fetched.Values[path.SecretsPublisher] = merged
+		}
+		err = MergeSecrets(merged, values, path.KeyPrefix, vaultmap.Spec.ConflictPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("Error merging secrets from %s: %v", vaultPath, err)
+		}
+	}
+
+	return fetched, nil
+}
+
+// MergeSecrets adds secrets to merged secrets, prefixing their keys. Keys
+// that are already in the merged secrets are resolved using the conflict
+// policy, which is one of error (the default), first or last.
+func MergeSecrets(merged map[string]string, secrets map[string]string, prefix string, policy string) error {
+	switch policy {
+	case "", "error", "first", "last":
+	default:
+		return fmt.Errorf("Invalid Conflict Policy: %s", policy)
+	}
+
+	for key, value := range secrets {
+		key = prefix + key
+		if _, exists := merged[key]; exists {
+			switch policy {
+			case "first":
+				continue
+			case "last":
+			default:
+				return fmt.Errorf("Secret %s is in more than one vault path", key)
+			}
+		}
+		merged[key] = value
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package inject
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	"k8s.io/api/apps/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeVault is a fake vault server that returns canned responses for paths
+type fakeVault map[string]string
+
+func (f fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
+	}
+	body, ok := f[path]
+	if !ok || r.Header.Get("X-Vault-Token") != "atoken" {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors": []}`))
+		return
+	}
+	w.Write([]byte(body))
+}
+
+func TestFetchSecrets(t *testing.T) {
+	server := httptest.NewServer(fakeVault{
+		"/v1/secret/default/envprinter": `{"data": {"mysecret": "Password123", "port": 5432}}`,
+	})
+	defer server.Close()
+
+	vaultmap := testVaultMap("1")
+	deployment := testDeployment(nil)
+
+	workload, container := deploymentContainer(deployment)
+	secrets, err := FetchSecrets(testVaultClient(t, server.URL), "atoken", vaultmap, workload, container)
+	if err != nil {
+		t.Fatalf("Fetching secrets resulted in an error: %v", err)
+	}
+	assertSecrets(t, map[string]string{"mysecret": "Password123", "port": "5432"}, secrets.Values["env"])
+	if secrets.Version != 0 {
+		t.Errorf("Got unexpected version: %d", secrets.Version)
+	}
+}
+
+func TestFetchSecretsIsolatedPerContainer(t *testing.T) {
+	server := httptest.NewServer(fakeVault{
+		"/v1/secret/default/envprinter": `{"data": {"mysecret": "Password123"}}`,
+		"/v1/secret/default/other":      `{"data": {"othersecret": "Password456"}}`,
+	})
+	defer server.Close()
+
+	client := testVaultClient(t, server.URL)
+	vaultmap := testVaultMap("1")
+	deployment := testDeployment(nil)
+	containers := &deployment.Spec.Template.Spec.Containers
+	*containers = append(*containers, corev1.Container{Name: "other", Image: "richardcase/envprinter:0.0.1"})
+	workload := DeploymentWorkload(deployment)
+
+	first, err := FetchSecrets(client, "atoken", vaultmap, workload, &(*containers)[0])
+	if err != nil {
+		t.Fatalf("Fetching secrets resulted in an error: %v", err)
+	}
+	second, err := FetchSecrets(client, "atoken", vaultmap, workload, &(*containers)[1])
+	if err != nil {
+		t.Fatalf("Fetching secrets resulted in an error: %v", err)
+	}
+
+	assertSecrets(t, map[string]string{"mysecret": "Password123"}, first.Values["env"])
+	assertSecrets(t, map[string]string{"othersecret": "Password456"}, second.Values["env"])
+}
+
+func TestFetchSecretsPinnedVersion(t *testing.T) {
+	server := httptest.NewServer(fakeVault{
+		"/v1/secret/data/default/envprinter?version=2": `{"data": {"data": {"mysecret": "OldPassword"}, "metadata": {"version": 2}}}`,
+	})
+	defer server.Close()
+
+	deployment := testDeployment(map[string]string{VersionAnnotation: "2"})
+
+	workload, container := deploymentContainer(deployment)
+	secrets, err := FetchSecrets(testVaultClient(t, server.URL), "atoken", testVaultMap("2"), workload, container)
+	if err != nil {
+		t.Fatalf("Fetching secrets resulted in an error: %v", err)
+	}
+	assertSecrets(t, map[string]string{"mysecret": "OldPassword"}, secrets.Values["env"])
+	if secrets.Version != 2 {
+		t.Errorf("Got unexpected version: %d", secrets.Version)
+	}
+}
+
+func TestFetchSecretsPinnedVersionMultiplePaths(t *testing.T) {
+	server := httptest.NewServer(fakeVault{
+		"/v1/secret/data/default/envprinter?version=2": `{"data": {"data": {"mysecret": "OldPassword"}, "metadata": {"version": 2}}}`,
+		"/v1/kv/shared/db":            `{"data": {"user": "admin"}}`,
+		"/v1/secret/data/team/apikey": `{"data": {"data": {"key": "abc"}, "metadata": {"version": 7}}}`,
+	})
+	defer server.Close()
+
+	vaultmap := testVaultMap("2")
+	vaultmap.Spec.VaultPaths = []v1alpha1.VaultPath{
+		{PathPattern: "/v1/kv/shared/db", KVVersion: "1", KeyPrefix: "db_"},
+		{PathPattern: "/v1/secret/team/apikey"},
+	}
+	deployment := testDeployment(map[string]string{VersionAnnotation: "2"})
+
+	workload, container := deploymentContainer(deployment)
+	secrets, err := FetchSecrets(testVaultClient(t, server.URL), "atoken", vaultmap, workload, container)
+	if err != nil {
+		t.Fatalf("Fetching secrets resulted in an error: %v", err)
+	}
+	assertSecrets(t, map[string]string{"mysecret": "OldPassword", "db_user": "admin", "key": "abc"}, secrets.Values["env"])
+	if secrets.Version != 2 {
+		t.Errorf("Got unexpected version: %d", secrets.Version)
+	}
+}
+
+func TestFetchSecretsPinnedVersionNotFound(t *testing.T) {
+	server := httptest.NewServer(fakeVault{
+		"/v1/secret/data/default/envprinter": `{"data": {"data": {"mysecret": "Password123"}, "metadata": {"version": 3}}}`,
+	})
+	defer server.Close()
+
+	deployment := testDeployment(map[string]string{VersionAnnotation: "4"})
+
+	workload, container := deploymentContainer(deployment)
+	if _, err := FetchSecrets(testVaultClient(t, server.URL), "atoken", testVaultMap("2"), workload, container); err == nil {
+		t.Error("Fetching secrets resulted in no error where an error was expected")
+	}
+}
+
+func TestFetchSecretsInvalidVersion(t *testing.T) {
+	deployment := testDeployment(map[string]string{VersionAnnotation: "latest"})
+
+	workload, container := deploymentContainer(deployment)
+	if _, err := FetchSecrets(nil, "atoken", testVaultMap("2"), workload, container); err == nil {
+		t.Error("Fetching secrets resulted in no error where an error was expected")
+	}
+}
+
+func TestFetchSecretsNotFound(t *testing.T) {
+	server := httptest.NewServer(fakeVault{})
+	defer server.Close()
+
+	workload, container := deploymentContainer(testDeployment(nil))
+	secrets, err := FetchSecrets(testVaultClient(t, server.URL), "atoken", testVaultMap("1"), workload, container)
+	if err != nil {
+		t.Fatalf("Fetching secrets resulted in an error: %v", err)
+	}
+	if secrets != nil {
+		t.Errorf("Expected no secrets but got %v", secrets)
+	}
+}
+
+func TestFetchSecretsMultiplePaths(t *testing.T) {
+	server := httptest.NewServer(fakeVault{
+		"/v1/secret/default/envprinter": `{"data": {"mysecret": "Password123"}}`,
+		"/v1/secret/shared/db":          `{"data": {"user": "admin", "password": "dbpass"}}`,
+		"/v1/secret/team/apikey":        `{"data": {"key": "abc"}}`,
+	})
+	defer server.Close()
+
+	vaultmap := testVaultMap("1")
+	vaultmap.Spec.VaultPaths = []v1alpha1.VaultPath{
+		{PathPattern: "/v1/secret/shared/db", KeyPrefix: "db_"},
+		{PathPattern: "/v1/secret/team/apikey", SecretsPublisher: "volume"},
+		{PathPattern: "/v1/secret/team/missing"},
+	}
+
+	workload, container := deploymentContainer(testDeployment(nil))
+	secrets, err := FetchSecrets(testVaultClient(t, server.URL), "atoken", vaultmap, workload, container)
+	if err != nil {
+		t.Fatalf("Fetching secrets resulted in an error: %v", err)
+	}
+	assertSecrets(t, map[string]string{"mysecret": "Password123", "db_user": "admin", "db_password": "dbpass"}, secrets.Values["env"])
+	assertSecrets(t, map[string]string{"key": "abc"}, secrets.Values["volume"])
+	if publishers := secrets.Publishers(); len(publishers) != 2 || publishers[0] != "env" || publishers[1] != "volume" {
+		t.Errorf("Got unexpected publishers: %v", publishers)
+	}
+}
+
+func TestFetchSecretsConflict(t *testing.T) {
+	server := httptest.NewServer(fakeVault{
+		"/v1/secret/default/envprinter": `{"data": {"mysecret": "Password123"}}`,
+		"/v1/secret/shared/envprinter":  `{"data": {"mysecret": "SharedPassword"}}`,
+	})
+	defer server.Close()
+
+	tests := []struct {
+		policy   string
+		expected string
+		err      bool
+	}{
+		{policy: "", err: true},
+		{policy: "error", err: true},
+		{policy: "first", expected: "Password123"},
+		{policy: "last", expected: "SharedPassword"},
+		{policy: "random", err: true},
+	}
+
+	for _, test := range tests {
+		vaultmap := testVaultMap("1")
+		vaultmap.Spec.ConflictPolicy = test.policy
+		vaultmap.Spec.VaultPaths = []v1alpha1.VaultPath{{PathPattern: "/v1/secret/shared/{{.ContainerName}}"}}
+
+		workload, container := deploymentContainer(testDeployment(nil))
+		secrets, err := FetchSecrets(testVaultClient(t, server.URL), "atoken", vaultmap, workload, container)
+		if test.err {
+			if err == nil {
+				t.Errorf("Policy %s: Fetching secrets resulted in no error where an error was expected", test.policy)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Policy %s: Fetching secrets resulted in an error: %v", test.policy, err)
+		}
+		assertSecrets(t, map[string]string{"mysecret": test.expected}, secrets.Values["env"])
+	}
+}
+
+func TestVaultPathsDefaults(t *testing.T) {
+	vaultmap := testVaultMap("2")
+	vaultmap.Spec.VaultPaths = []v1alpha1.VaultPath{
+		{PathPattern: "/v1/secret/shared", KVVersion: "1", SecretsPublisher: "volume"},
+	}
+
+	paths := VaultPaths(vaultmap)
+	if len(paths) != 2 {
+		t.Fatalf("Got unexpected number of paths: %d", len(paths))
+	}
+	if paths[0].PathPattern != vaultmap.Spec.VaultPathPattern || paths[0].KVVersion != "2" || paths[0].SecretsPublisher != "env" {
+		t.Errorf("Got unexpected first path: %v", paths[0])
+	}
+	if paths[1].KVVersion != "1" || paths[1].SecretsPublisher != "volume" {
+		t.Errorf("Got unexpected second path: %v", paths[1])
+	}
+	if vaultmap.Spec.VaultPaths[0].KVVersion != "1" || len(vaultmap.Spec.VaultPaths) != 1 {
+		t.Errorf("Expected vault map not to be modified")
+	}
+}
+
+func testVaultClient(t *testing.T, address string) *vault.Client {
+	client, err := vault.NewClient(&vault.Config{Address: address, HttpClient: http.DefaultClient})
+	if err != nil {
+		t.Fatalf("Creating vault client resulted in an error: %v", err)
+	}
+	return client
+}
+
+func testVaultMap(kvVersion string) *v1alpha1.VaultMap {
+	return &v1alpha1.VaultMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "default-vaultmap"},
+		Spec: v1alpha1.MapSpec{
+			VaultPathPattern: "/v1/secret/{{.Namespace}}/{{.ContainerName}}",
+			KVVersion:        kvVersion,
+			SecretsPublisher: "env",
+		},
+	}
+}
+
+func testDeployment(annotations map[string]string) *v1beta1.Deployment {
+	return &v1beta1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "envprinter",
+			Annotations: annotations,
+		},
+		Spec: v1beta1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "envprinter", Image: "richardcase/envprinter:0.0.1"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// deploymentContainer returns the workload and first container of a deployment
+func deploymentContainer(deployment *v1beta1.Deployment) (*model.Workload, *corev1.Container) {
+	return DeploymentWorkload(deployment), &deployment.Spec.Template.Spec.Containers[0]
+}
@@ -0,0 +1,314 @@
+package inject
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	clientset "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned"
+	listers "github.com/richardcase/vault-initializer/pkg/client/listers/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	"github.com/richardcase/vault-initializer/pkg/vaultclient"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// InitializedAnnotation flags that a pod template has had vault secrets injected
+	InitializedAnnotation = "vault-secrets-initialized"
+	// ResolvedVersionAnnotation records the KV v2 secret version injected into a pod template
+	ResolvedVersionAnnotation = "vault-secrets-version"
+	// ContainersAnnotation lists the containers of a workload that get secrets, separated by commas
+	ContainersAnnotation = "vault.initializer/containers"
+	// MapAnnotation names the vault map used for a workload instead of matching selectors
+	MapAnnotation = "vault.initializer/map"
+)
+
+// Injector injects secrets from vault into workloads. It holds everything
+// that is shared between the initializer and the admission webhook.
+type Injector struct {
+	kubeclientset     kubernetes.Interface
+	mapclientset      clientset.Interface
+	mapsLister        listers.VaultMapLister
+	clusterMapsLister listers.ClusterVaultMapLister
+	vaultClient       *vault.Client
+	tokens            *vaultclient.TokenManager
+	config            *model.Config
+	recorder          record.EventRecorder
+	status            *statusUpdater
+}
+
+// NewInjector returns a new injector
+func NewInjector(
+	kubeclientset kubernetes.Interface,
+	mapclientset clientset.Interface,
+	mapsLister listers.VaultMapLister,
+	clusterMapsLister listers.ClusterVaultMapLister,
+	vaultClient *vault.Client,
+	tokens *vaultclient.TokenManager,
+	config *model.Config,
+	recorder record.EventRecorder) *Injector {
+
+	return &Injector{
+		kubeclientset:     kubeclientset,
+		mapclientset:      mapclientset,
+		mapsLister:        mapsLister,
+		clusterMapsLister: clusterMapsLister,
+		vaultClient:       vaultClient,
+		tokens:            tokens,
+		config:            config,
+		recorder:          recorder,
+		status:            newStatusUpdater(mapclientset),
+	}
+}
+
+// Start starts updating the status of vault maps in the background
+func (in *Injector) Start(stopCh <-chan struct{}) {
+	go in.status.run(stopCh)
+}
+
+// Inject injects the secrets for a workload, modifying the pod template of
+// the workload in place. False is returned if the workload was skipped and
+// hasn't been changed. Errors injecting the secrets of a vault map are
+// returned as an InjectionError.
+func (in *Injector) Inject(workload *model.Workload) (bool, error) {
+	if in.config.IgnoreSystemNamespaces && workload.Namespace == "kube-system" {
+		glog.Infof("Ignoring workloads in kube-system namespace")
+		return false, nil
+	}
+
+	if in.config.RequireAnnotation {
+		_, ok := workload.Annotations[in.config.AnnotatioName]
+		if !ok {
+			glog.V(2).Infof("Required '%s' annotation missing; skipping vault injection", in.config.AnnotatioName)
+			return false, nil
+		}
+	}
+
+	if _, ok := workload.Template.Annotations[InitializedAnnotation]; ok {
+		glog.V(2).Infof("%s %s already has vault secrets; skipping vault injection", workload.Kind, workload.Name)
+		return false, nil
+	}
+
+	vaultmap, err := in.vaultMap(workload)
+	if err != nil {
+		return false, err
+	}
+	if vaultmap == nil {
+		glog.V(2).Infof("No VaultMap for %s %s in namespace %s; skipping vault injection", workload.Kind, workload.Name, workload.Namespace)
+		return false, nil
+	}
+
+	injected, err := in.injectVaultMap(workload, vaultmap)
+	if injected || err != nil {
+		in.updateStatus(vaultmap, workload, err)
+	}
+	if err != nil {
+		return false, &InjectionError{Err: err, FailurePolicy: vaultmap.Spec.FailurePolicy}
+	}
+	return injected, nil
+}
+
+// injectVaultMap injects the secrets described by a vault map into the
+// containers of a workload
+func (in *Injector) injectVaultMap(workload *model.Workload, vaultmap *v1alpha1.VaultMap) (bool, error) {
+	containers, err := targetContainers(workload, vaultmap.Spec.InjectInitContainers)
+	if err != nil {
+		return false, err
+	}
+
+	token, err := in.tokens.Token()
+	if err != nil {
+		return false, err
+	}
+
+	versions := make(map[string]int)
+	for _, container := range containers {
+		secrets, err := FetchSecrets(in.vaultClient, token, vaultmap, workload, container)
+		if err != nil {
+			return false, err
+		}
+		if secrets == nil {
+			glog.V(2).Infof("No secrets for container %s of %s %s", container.Name, workload.Kind, workload.Name)
+			continue
+		}
+
+		for _, publisherType := range secrets.Publishers() {
+			publisher, err := CreatePublisher(publisherType)
+			if err != nil {
+				return false, err
+			}
+			err = publisher.PublishSecrets(vaultmap, in.kubeclientset, workload, container, secrets.Values[publisherType])
+			if err != nil {
+				return false, err
+			}
+		}
+		versions[container.Name] = secrets.Version
+	}
+	if len(versions) == 0 {
+		return false, nil
+	}
+
+	// Flag that this container has vault secrets
+	if workload.Template.Annotations == nil {
+		annotations := make(map[string]string)
+		workload.Template.SetAnnotations(annotations)
+	}
+	workload.Template.Annotations[InitializedAnnotation] = "true"
+	if version := resolvedVersion(versions); version != "" {
+		workload.Template.Annotations[ResolvedVersionAnnotation] = version
+	}
+
+	return true, nil
+}
+
+// vaultMap returns the vault map named in the map annotation of a workload.
+// Without the annotation it returns the vault map with the highest precedence
+// that applies to the workload, falling back to the cluster vault maps if no
+// vault map in the namespace applies. Nil is returned if none apply.
+func (in *Injector) vaultMap(workload *model.Workload) (*v1alpha1.VaultMap, error) {
+	if name, ok := workload.Annotations[MapAnnotation]; ok {
+		return in.namedVaultMap(workload, name)
+	}
+
+	maps, err := in.mapsLister.VaultMaps(workload.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	vaultmap, err := in.selectVaultMap(workload, "VaultMaps", maps)
+	if vaultmap != nil || err != nil {
+		return vaultmap, err
+	}
+
+	clusterMaps, err := in.clusterMapsLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	return in.selectVaultMap(workload, "ClusterVaultMaps", ClusterVaultMaps(clusterMaps))
+}
+
+// namedVaultMap returns the vault map with a name in the namespace of a
+// workload or the cluster vault map with the name if there isn't one
+func (in *Injector) namedVaultMap(workload *model.Workload, name string) (*v1alpha1.VaultMap, error) {
+	vaultmap, err := in.mapsLister.VaultMaps(workload.Namespace).Get(name)
+	if err == nil {
+		return vaultmap, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	clusterMap, err := in.clusterMapsLister.Get(name)
+	if err == nil {
+		return ClusterVaultMaps([]*v1alpha1.ClusterVaultMap{clusterMap})[0], nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	in.recordEvent(workload, corev1.EventTypeWarning, "VaultMapNotFound", "VaultMap %s in annotation %s not found in namespace %s", name, MapAnnotation, workload.Namespace)
+	return nil, fmt.Errorf("VaultMap %s in annotation %s not found in namespace %s", name, MapAnnotation, workload.Namespace)
+}
+
+// selectVaultMap returns the vault map with the highest precedence that
+// applies to a workload and records an event if more than one applies
+func (in *Injector) selectVaultMap(workload *model.Workload, kind string, maps []*v1alpha1.VaultMap) (*v1alpha1.VaultMap, error) {
+	matched, err := MatchVaultMaps(maps, workload)
+	if err != nil {
+		return nil, err
+	}
+	if len(matched) == 0 {
+		return nil, nil
+	}
+
+	if len(matched) > 1 {
+		names := make([]string, 0, len(matched))
+		for _, vaultmap := range matched {
+			names = append(names, vaultmap.Name)
+		}
+		glog.Warningf("%s %s all match %s %s; using %s", kind, strings.Join(names, ", "), workload.Kind, workload.Name, matched[0].Name)
+		in.recordEvent(workload, corev1.EventTypeWarning, "MultipleVaultMaps", "%s %s all match, using %s", kind, strings.Join(names, ", "), matched[0].Name)
+	}
+	return matched[0], nil
+}
+
+// recordEvent records an event against the resource of a workload
+func (in *Injector) recordEvent(workload *model.Workload, eventtype, reason, messageFmt string, args ...interface{}) {
+	if in.recorder == nil || workload.Object == nil {
+		return
+	}
+	in.recorder.Eventf(workload.Object, eventtype, reason, messageFmt, args...)
+}
+
+// targetContainers returns the containers of a workload that should get
+// secrets. These are listed in the containers annotation or are all of the
+// containers if there's no annotation. Init containers are only included
+// if requested.
+func targetContainers(workload *model.Workload, initContainers bool) ([]*corev1.Container, error) {
+	var containers []*corev1.Container
+	if initContainers {
+		for i := range workload.Template.Spec.InitContainers {
+			containers = append(containers, &workload.Template.Spec.InitContainers[i])
+		}
+	}
+	for i := range workload.Template.Spec.Containers {
+		containers = append(containers, &workload.Template.Spec.Containers[i])
+	}
+
+	names, ok := workload.Annotations[ContainersAnnotation]
+	if !ok {
+		return containers, nil
+	}
+
+	var targets []*corev1.Container
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, container := range containers {
+			if container.Name == name {
+				targets = append(targets, container)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Container %s in annotation %s not found", name, ContainersAnnotation)
+		}
+	}
+	return targets, nil
+}
+
+// resolvedVersion formats the KV v2 secret versions injected into the
+// containers. A single container just has the version, otherwise the
+// versions are listed as container=version.
+func resolvedVersion(versions map[string]int) string {
+	var names []string
+	for name, version := range versions {
+		if version > 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	if len(versions) == 1 {
+		return strconv.Itoa(versions[names[0]])
+	}
+
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", name, versions[name]))
+	}
+	return strings.Join(pairs, ",")
+}
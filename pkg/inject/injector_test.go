@@ -0,0 +1,243 @@
+package inject
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	mapfake "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/fake"
+	listers "github.com/richardcase/vault-initializer/pkg/client/listers/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestVaultMapAnnotation(t *testing.T) {
+	payments := selectorVaultMap("payments", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "payments"}})
+	injector, _ := testInjector([]*v1alpha1.VaultMap{selectorVaultMap("default", nil), payments}, nil)
+
+	deployment := testDeployment(map[string]string{MapAnnotation: "payments"})
+	vaultmap, err := injector.vaultMap(DeploymentWorkload(deployment))
+	if err != nil {
+		t.Fatalf("Getting vault map resulted in an error: %v", err)
+	}
+	if vaultmap == nil || vaultmap.Name != "payments" {
+		t.Errorf("Got unexpected vault map: %v", vaultmap)
+	}
+
+	// Without the annotation the selectors are used
+	vaultmap, err = injector.vaultMap(DeploymentWorkload(testDeployment(nil)))
+	if err != nil {
+		t.Fatalf("Getting vault map resulted in an error: %v", err)
+	}
+	if vaultmap == nil || vaultmap.Name != "default" {
+		t.Errorf("Got unexpected vault map: %v", vaultmap)
+	}
+}
+
+func TestVaultMapAnnotationNotFound(t *testing.T) {
+	injector, recorder := testInjector([]*v1alpha1.VaultMap{selectorVaultMap("default", nil)}, nil)
+
+	deployment := testDeployment(map[string]string{MapAnnotation: "payments"})
+	if _, err := injector.Inject(DeploymentWorkload(deployment)); err == nil {
+		t.Fatal("Injecting secrets resulted in no error where an error was expected")
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "VaultMapNotFound") {
+			t.Errorf("Got unexpected event: %s", event)
+		}
+	default:
+		t.Error("Expected an event to be recorded")
+	}
+}
+
+func TestClusterVaultMapFallback(t *testing.T) {
+	clusterMap := &v1alpha1.ClusterVaultMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-default"},
+		Spec:       testVaultMap("1").Spec,
+	}
+	payments := selectorVaultMap("payments", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "payments"}})
+	injector, _ := testInjector([]*v1alpha1.VaultMap{payments}, []*v1alpha1.ClusterVaultMap{clusterMap})
+
+	// The namespaced vault map applies to the workload so takes precedence
+	deployment := testDeployment(nil)
+	deployment.Spec.Template.Labels = map[string]string{"app": "payments"}
+	vaultmap, err := injector.vaultMap(DeploymentWorkload(deployment))
+	if err != nil {
+		t.Fatalf("Getting vault map resulted in an error: %v", err)
+	}
+	if vaultmap == nil || vaultmap.Name != "payments" {
+		t.Errorf("Got unexpected vault map: %v", vaultmap)
+	}
+
+	// Otherwise the cluster vault map is used
+	vaultmap, err = injector.vaultMap(DeploymentWorkload(testDeployment(nil)))
+	if err != nil {
+		t.Fatalf("Getting vault map resulted in an error: %v", err)
+	}
+	if vaultmap == nil || vaultmap.Name != "cluster-default" || vaultmap.Spec.SecretsPublisher != "env" {
+		t.Errorf("Got unexpected vault map: %v", vaultmap)
+	}
+
+	// Cluster vault maps can be named in the annotation
+	vaultmap, err = injector.vaultMap(DeploymentWorkload(testDeployment(map[string]string{MapAnnotation: "cluster-default"})))
+	if err != nil {
+		t.Fatalf("Getting vault map resulted in an error: %v", err)
+	}
+	if vaultmap == nil || vaultmap.Name != "cluster-default" {
+		t.Errorf("Got unexpected vault map: %v", vaultmap)
+	}
+}
+
+func TestVaultMapMultipleMatchesEvent(t *testing.T) {
+	injector, recorder := testInjector([]*v1alpha1.VaultMap{selectorVaultMap("default", nil), selectorVaultMap("other", nil)}, nil)
+
+	vaultmap, err := injector.vaultMap(DeploymentWorkload(testDeployment(nil)))
+	if err != nil {
+		t.Fatalf("Getting vault map resulted in an error: %v", err)
+	}
+	if vaultmap.Name != "default" {
+		t.Errorf("Got unexpected vault map: %s", vaultmap.Name)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "MultipleVaultMaps") {
+			t.Errorf("Got unexpected event: %s", event)
+		}
+	default:
+		t.Error("Expected an event to be recorded")
+	}
+}
+
+func TestTargetContainers(t *testing.T) {
+	tests := []struct {
+		annotations map[string]string
+		expected    []string
+	}{
+		{annotations: nil, expected: []string{"envprinter", "sidecar", "proxy"}},
+		{annotations: map[string]string{ContainersAnnotation: "sidecar"}, expected: []string{"sidecar"}},
+		{annotations: map[string]string{ContainersAnnotation: "proxy, envprinter"}, expected: []string{"proxy", "envprinter"}},
+		{annotations: map[string]string{ContainersAnnotation: ""}, expected: []string{}},
+	}
+
+	for _, test := range tests {
+		deployment := testDeployment(test.annotations)
+		deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers,
+			corev1.Container{Name: "sidecar"},
+			corev1.Container{Name: "proxy"},
+		)
+
+		containers, err := targetContainers(DeploymentWorkload(deployment), false)
+		if err != nil {
+			t.Fatalf("Getting target containers resulted in an error: %v", err)
+		}
+		if len(containers) != len(test.expected) {
+			t.Fatalf("Got unexpected number of containers for %v: %d", test.annotations, len(containers))
+		}
+		for i, container := range containers {
+			if container.Name != test.expected[i] {
+				t.Errorf("Got unexpected container %s, expected %s", container.Name, test.expected[i])
+			}
+		}
+	}
+}
+
+func TestTargetContainersRefersToTemplate(t *testing.T) {
+	deployment := testDeployment(nil)
+
+	containers, err := targetContainers(DeploymentWorkload(deployment), false)
+	if err != nil {
+		t.Fatalf("Getting target containers resulted in an error: %v", err)
+	}
+	containers[0].Env = append(containers[0].Env, corev1.EnvVar{Name: "mysecret"})
+
+	if len(deployment.Spec.Template.Spec.Containers[0].Env) != 1 {
+		t.Errorf("Expected changes to the container to change the pod template")
+	}
+}
+
+func TestTargetContainersNotFound(t *testing.T) {
+	deployment := testDeployment(map[string]string{ContainersAnnotation: "missing"})
+
+	if _, err := targetContainers(DeploymentWorkload(deployment), false); err == nil {
+		t.Error("Getting target containers resulted in no error where an error was expected")
+	}
+}
+
+func TestTargetInitContainers(t *testing.T) {
+	tests := []struct {
+		annotations    map[string]string
+		initContainers bool
+		expected       []string
+	}{
+		{annotations: nil, initContainers: false, expected: []string{"envprinter"}},
+		{annotations: nil, initContainers: true, expected: []string{"migrate", "envprinter"}},
+		{annotations: map[string]string{ContainersAnnotation: "migrate"}, initContainers: true, expected: []string{"migrate"}},
+	}
+
+	for _, test := range tests {
+		deployment := testDeployment(test.annotations)
+		deployment.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "migrate"}}
+
+		containers, err := targetContainers(DeploymentWorkload(deployment), test.initContainers)
+		if err != nil {
+			t.Fatalf("Getting target containers resulted in an error: %v", err)
+		}
+		if len(containers) != len(test.expected) {
+			t.Fatalf("Got unexpected number of containers for %v: %d", test.annotations, len(containers))
+		}
+		for i, container := range containers {
+			if container.Name != test.expected[i] {
+				t.Errorf("Got unexpected container %s, expected %s", container.Name, test.expected[i])
+			}
+		}
+	}
+
+	// Init containers can't be listed in the annotation unless they are enabled
+	deployment := testDeployment(map[string]string{ContainersAnnotation: "migrate"})
+	deployment.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "migrate"}}
+	if _, err := targetContainers(DeploymentWorkload(deployment), false); err == nil {
+		t.Error("Getting target containers resulted in no error where an error was expected")
+	}
+}
+
+func TestResolvedVersion(t *testing.T) {
+	tests := []struct {
+		versions map[string]int
+		expected string
+	}{
+		{versions: map[string]int{"envprinter": 0}, expected: ""},
+		{versions: map[string]int{"envprinter": 3}, expected: "3"},
+		{versions: map[string]int{"sidecar": 1, "envprinter": 3}, expected: "envprinter=3,sidecar=1"},
+		{versions: map[string]int{"sidecar": 0, "envprinter": 3}, expected: "envprinter=3"},
+	}
+
+	for _, test := range tests {
+		if version := resolvedVersion(test.versions); version != test.expected {
+			t.Errorf("Got unexpected version for %v: %s", test.versions, version)
+		}
+	}
+}
+
+// testInjector creates an injector for the supplied vault maps without a vault client
+func testInjector(maps []*v1alpha1.VaultMap, clusterMaps []*v1alpha1.ClusterVaultMap) (*Injector, *record.FakeRecorder) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	var objects []runtime.Object
+	for _, vaultmap := range maps {
+		indexer.Add(vaultmap)
+		objects = append(objects, vaultmap)
+	}
+	clusterIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, clusterMap := range clusterMaps {
+		clusterIndexer.Add(clusterMap)
+	}
+	recorder := record.NewFakeRecorder(10)
+	injector := NewInjector(nil, mapfake.NewSimpleClientset(objects...), listers.NewVaultMapLister(indexer), listers.NewClusterVaultMapLister(clusterIndexer), nil, nil, &model.Config{}, recorder)
+	return injector, recorder
+}
@@ -0,0 +1,68 @@
+package inject
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// MatchVaultMaps returns the vault maps that apply to a workload, in order of
+// precedence. A map applies if its selector matches the labels of the pod
+// template of the workload, maps without a selector apply to all workloads.
+// Maps with more specific selectors, that is with more requirements, take
+// precedence and maps with the same number of requirements are ordered by name.
+func MatchVaultMaps(maps []*v1alpha1.VaultMap, workload *model.Workload) ([]*v1alpha1.VaultMap, error) {
+	podLabels := labels.Set(workload.Template.Labels)
+
+	var matched []*v1alpha1.VaultMap
+	for _, vaultmap := range maps {
+		if vaultmap.Spec.Selector == nil {
+			matched = append(matched, vaultmap)
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(vaultmap.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid selector in VaultMap %s: %v", vaultmap.Name, err)
+		}
+		if selector.Matches(podLabels) {
+			matched = append(matched, vaultmap)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		si, sj := selectorRequirements(matched[i]), selectorRequirements(matched[j])
+		if si != sj {
+			return si > sj
+		}
+		return matched[i].Name < matched[j].Name
+	})
+	return matched, nil
+}
+
+// ClusterVaultMaps returns vault maps with the names and specs of cluster
+// vault maps so that they can be used in place of vault maps. The vault maps
+// don't have a namespace.
+func ClusterVaultMaps(clusterMaps []*v1alpha1.ClusterVaultMap) []*v1alpha1.VaultMap {
+	maps := make([]*v1alpha1.VaultMap, 0, len(clusterMaps))
+	for _, clusterMap := range clusterMaps {
+		maps = append(maps, &v1alpha1.VaultMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   clusterMap.Name,
+				Labels: clusterMap.Labels,
+			},
+			Spec: *clusterMap.Spec.DeepCopy(),
+		})
+	}
+	return maps
+}
+
+func selectorRequirements(vaultmap *v1alpha1.VaultMap) int {
+	if vaultmap.Spec.Selector == nil {
+		return 0
+	}
+	return len(vaultmap.Spec.Selector.MatchLabels) + len(vaultmap.Spec.Selector.MatchExpressions)
+}
@@ -0,0 +1,76 @@
+package inject
+
+import (
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatchVaultMaps(t *testing.T) {
+	maps := []*v1alpha1.VaultMap{
+		selectorVaultMap("zz-default", nil),
+		selectorVaultMap("aa-default", nil),
+		selectorVaultMap("app", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "envprinter"}}),
+		selectorVaultMap("app-tier", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "envprinter", "tier": "backend"}}),
+		selectorVaultMap("other", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}}),
+		selectorVaultMap("expression", &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"backend", "frontend"}},
+			},
+		}),
+	}
+
+	deployment := testDeployment(nil)
+	deployment.Spec.Template.Labels = map[string]string{"app": "envprinter", "tier": "backend"}
+
+	matched, err := MatchVaultMaps(maps, DeploymentWorkload(deployment))
+	if err != nil {
+		t.Fatalf("Matching vault maps resulted in an error: %v", err)
+	}
+
+	expected := []string{"app-tier", "app", "expression", "aa-default", "zz-default"}
+	if len(matched) != len(expected) {
+		t.Fatalf("Got unexpected number of vault maps: %d", len(matched))
+	}
+	for i, vaultmap := range matched {
+		if vaultmap.Name != expected[i] {
+			t.Errorf("Got unexpected vault map %s at %d, expected %s", vaultmap.Name, i, expected[i])
+		}
+	}
+}
+
+func TestMatchVaultMapsNoMatch(t *testing.T) {
+	maps := []*v1alpha1.VaultMap{
+		selectorVaultMap("other", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}}),
+	}
+
+	matched, err := MatchVaultMaps(maps, DeploymentWorkload(testDeployment(nil)))
+	if err != nil {
+		t.Fatalf("Matching vault maps resulted in an error: %v", err)
+	}
+	if len(matched) != 0 {
+		t.Errorf("Expected no vault maps but got %d", len(matched))
+	}
+}
+
+func TestMatchVaultMapsInvalidSelector(t *testing.T) {
+	maps := []*v1alpha1.VaultMap{
+		selectorVaultMap("invalid", &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: "Unknown"},
+			},
+		}),
+	}
+
+	if _, err := MatchVaultMaps(maps, DeploymentWorkload(testDeployment(nil))); err == nil {
+		t.Error("Matching vault maps resulted in no error where an error was expected")
+	}
+}
+
+func selectorVaultMap(name string, selector *metav1.LabelSelector) *v1alpha1.VaultMap {
+	vaultmap := testVaultMap("1")
+	vaultmap.Name = name
+	vaultmap.Spec.Selector = selector
+	return vaultmap
+}
@@ -4,13 +4,15 @@ import (
 	"errors"
 
 	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
-	"k8s.io/api/apps/v1beta1"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
 // Publisher is an interface that defines what publishers need to implement.
+// The container is one of the containers in the pod template of the workload.
 type Publisher interface {
-	PublishSecrets(vaultmap *v1alpha1.VaultMap, clientset *kubernetes.Clientset, deployment *v1beta1.Deployment, secrets map[string]string) error
+	PublishSecrets(vaultmap *v1alpha1.VaultMap, clientset kubernetes.Interface, workload *model.Workload, container *corev1.Container, secrets map[string]string) error
 }
 
 // CreatePublisher create a new secrets publisher
@@ -0,0 +1,23 @@
+package inject
+
+import (
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	"k8s.io/client-go/kubernetes"
+)
+
+// publishToTargets publishes secrets to each of the containers of a workload
+// that a vault map targets, like the injector does
+func publishToTargets(t *testing.T, publisher Publisher, vaultmap *v1alpha1.VaultMap, clientset kubernetes.Interface, workload *model.Workload, secrets map[string]string) {
+	containers, err := targetContainers(workload, vaultmap.Spec.InjectInitContainers)
+	if err != nil {
+		t.Fatalf("Getting target containers resulted in an error: %v", err)
+	}
+	for _, container := range containers {
+		if err = publisher.PublishSecrets(vaultmap, clientset, workload, container, secrets); err != nil {
+			t.Fatalf("Publishing secrets to container %s resulted in an error: %v", container.Name, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package inject
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ConvertSecrets converts the values of a secret read from vault into strings.
+// Strings are used as is, other scalars are formatted and lists and objects
+// are JSON encoded. If flatten is set nested objects are instead expanded
+// into keys joined with a dot, e.g. {"db": {"user": "admin"}} becomes db.user.
+func ConvertSecrets(data map[string]interface{}, flatten bool) (map[string]string, error) {
+	secrets := make(map[string]string)
+	if err := convertSecrets(secrets, "", data, flatten); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+func convertSecrets(secrets map[string]string, prefix string, data map[string]interface{}, flatten bool) error {
+	for key, value := range data {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok && flatten {
+			if err := convertSecrets(secrets, key, nested, flatten); err != nil {
+				return err
+			}
+			continue
+		}
+
+		converted, err := convertValue(value)
+		if err != nil {
+			return fmt.Errorf("Unable to convert secret %s: %v", key, err)
+		}
+		if _, exists := secrets[key]; exists {
+			return fmt.Errorf("Secret %s is defined more than once", key)
+		}
+		secrets[key] = converted
+	}
+	return nil
+}
+
+func convertValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+}
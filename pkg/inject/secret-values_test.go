@@ -0,0 +1,117 @@
+package inject
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConvertSecretsStrings(t *testing.T) {
+	secrets, err := ConvertSecrets(map[string]interface{}{"password": "Password123"}, false)
+	if err != nil {
+		t.Fatalf("Converting secrets resulted in an error: %v", err)
+	}
+	if secrets["password"] != "Password123" {
+		t.Errorf("Got unexpected password: %s", secrets["password"])
+	}
+}
+
+func TestConvertSecretsNumbers(t *testing.T) {
+	data := map[string]interface{}{
+		"port":    json.Number("5432"),
+		"ratio":   json.Number("0.75"),
+		"float":   1.5,
+		"integer": 42,
+	}
+
+	secrets, err := ConvertSecrets(data, false)
+	if err != nil {
+		t.Fatalf("Converting secrets resulted in an error: %v", err)
+	}
+	expected := map[string]string{"port": "5432", "ratio": "0.75", "float": "1.5", "integer": "42"}
+	assertSecrets(t, expected, secrets)
+}
+
+func TestConvertSecretsBooleans(t *testing.T) {
+	secrets, err := ConvertSecrets(map[string]interface{}{"enabled": true, "debug": false}, false)
+	if err != nil {
+		t.Fatalf("Converting secrets resulted in an error: %v", err)
+	}
+	assertSecrets(t, map[string]string{"enabled": "true", "debug": "false"}, secrets)
+}
+
+func TestConvertSecretsNull(t *testing.T) {
+	secrets, err := ConvertSecrets(map[string]interface{}{"empty": nil}, false)
+	if err != nil {
+		t.Fatalf("Converting secrets resulted in an error: %v", err)
+	}
+	assertSecrets(t, map[string]string{"empty": ""}, secrets)
+}
+
+func TestConvertSecretsLists(t *testing.T) {
+	data := map[string]interface{}{
+		"hosts": []interface{}{"db1", "db2", json.Number("3")},
+	}
+
+	secrets, err := ConvertSecrets(data, false)
+	if err != nil {
+		t.Fatalf("Converting secrets resulted in an error: %v", err)
+	}
+	assertSecrets(t, map[string]string{"hosts": `["db1","db2",3]`}, secrets)
+}
+
+func TestConvertSecretsObjects(t *testing.T) {
+	data := map[string]interface{}{
+		"db": map[string]interface{}{"user": "admin", "port": json.Number("5432")},
+	}
+
+	secrets, err := ConvertSecrets(data, false)
+	if err != nil {
+		t.Fatalf("Converting secrets resulted in an error: %v", err)
+	}
+	assertSecrets(t, map[string]string{"db": `{"port":5432,"user":"admin"}`}, secrets)
+}
+
+func TestConvertSecretsFlattenObjects(t *testing.T) {
+	data := map[string]interface{}{
+		"db": map[string]interface{}{
+			"user": "admin",
+			"tls":  map[string]interface{}{"enabled": true},
+		},
+		"hosts":  []interface{}{"db1", "db2"},
+		"apikey": "abc123",
+	}
+
+	secrets, err := ConvertSecrets(data, true)
+	if err != nil {
+		t.Fatalf("Converting secrets resulted in an error: %v", err)
+	}
+	expected := map[string]string{
+		"db.user":        "admin",
+		"db.tls.enabled": "true",
+		"hosts":          `["db1","db2"]`,
+		"apikey":         "abc123",
+	}
+	assertSecrets(t, expected, secrets)
+}
+
+func TestConvertSecretsFlattenConflict(t *testing.T) {
+	data := map[string]interface{}{
+		"db":      map[string]interface{}{"user": "admin"},
+		"db.user": "root",
+	}
+
+	if _, err := ConvertSecrets(data, true); err == nil {
+		t.Error("Converting secrets resulted in no error where an error was expected")
+	}
+}
+
+func assertSecrets(t *testing.T, expected, actual map[string]string) {
+	if len(expected) != len(actual) {
+		t.Errorf("Expected %d secrets but got %d: %v", len(expected), len(actual), actual)
+	}
+	for key, value := range expected {
+		if actual[key] != value {
+			t.Errorf("Got unexpected value for %s: %s", key, actual[key])
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package inject
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	clientset "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// maxStatusWorkloads limits the number of workloads listed in the status of a vault map
+const maxStatusWorkloads = 100
+
+// injectionResult is the result of injecting secrets into a workload that
+// is waiting to be recorded in the status of a vault map
+type injectionResult struct {
+	workload *model.Workload
+	err      error
+	time     metav1.Time
+}
+
+// statusUpdater records injection results in the status of vault maps in the
+// background, so injecting secrets doesn't wait for the API server. Results
+// for a vault map that are queued together are recorded in a single update.
+type statusUpdater struct {
+	mapclientset clientset.Interface
+	queue        workqueue.Interface
+
+	lock    sync.Mutex
+	pending map[string][]injectionResult
+}
+
+func newStatusUpdater(mapclientset clientset.Interface) *statusUpdater {
+	return &statusUpdater{
+		mapclientset: mapclientset,
+		queue:        workqueue.NewNamed("VaultMapStatus"),
+		pending:      make(map[string][]injectionResult),
+	}
+}
+
+// run records queued results until the stop channel is closed
+func (u *statusUpdater) run(stopCh <-chan struct{}) {
+	go wait.Until(func() {
+		for u.processNextItem() {
+		}
+	}, time.Second, stopCh)
+
+	<-stopCh
+	u.queue.ShutDown()
+}
+
+// updateStatus queues the result of injecting secrets into a workload to be
+// recorded in the status of a vault map. Cluster vault maps don't have a
+// status. Failing to update the status doesn't fail the injection.
+func (in *Injector) updateStatus(vaultmap *v1alpha1.VaultMap, workload *model.Workload, injectErr error) {
+	if vaultmap.Namespace == "" {
+		return
+	}
+
+	key := vaultmap.Namespace + "/" + vaultmap.Name
+	result := injectionResult{
+		workload: &model.Workload{Kind: workload.Kind, Name: workload.Name},
+		err:      injectErr,
+		time:     metav1.NewTime(time.Now()),
+	}
+
+	in.status.lock.Lock()
+	in.status.pending[key] = append(in.status.pending[key], result)
+	in.status.lock.Unlock()
+	in.status.queue.Add(key)
+}
+
+func (u *statusUpdater) processNextItem() bool {
+	obj, shutdown := u.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer u.queue.Done(obj)
+
+	key := obj.(string)
+	u.lock.Lock()
+	results := u.pending[key]
+	delete(u.pending, key)
+	u.lock.Unlock()
+	if len(results) == 0 {
+		return true
+	}
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		glog.Errorf("Invalid VaultMap key %s: %v", key, err)
+		return true
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := u.mapclientset.VaultinitV1alpha1().VaultMaps(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		updated := current.DeepCopy()
+		for _, result := range results {
+			RecordInjection(&updated.Status, updated.Generation, result.workload, result.err, result.time)
+		}
+		_, err = u.mapclientset.VaultinitV1alpha1().VaultMaps(namespace).UpdateStatus(updated)
+		return err
+	})
+	if err != nil {
+		glog.Warningf("Error updating status of VaultMap %s: %v", key, err)
+	}
+	return true
+}
+
+// RecordInjection records the result of injecting secrets into a workload
+// in the status of a vault map. A successful injection moves the workload to
+// the end of the list of workloads and clears the last error.
+func RecordInjection(status *v1alpha1.MapStatus, generation int64, workload *model.Workload, injectErr error, now metav1.Time) {
+	status.ObservedGeneration = generation
+	if injectErr != nil {
+		status.LastError = injectErr.Error()
+		return
+	}
+
+	status.LastError = ""
+	status.LastInjectionTime = &now
+
+	ref := v1alpha1.WorkloadReference{Kind: workload.Kind, Name: workload.Name}
+	workloads := make([]v1alpha1.WorkloadReference, 0, len(status.Workloads)+1)
+	for _, existing := range status.Workloads {
+		if existing != ref {
+			workloads = append(workloads, existing)
+		}
+	}
+	workloads = append(workloads, ref)
+	if len(workloads) > maxStatusWorkloads {
+		workloads = workloads[len(workloads)-maxStatusWorkloads:]
+	}
+	status.Workloads = workloads
+}
@@ -0,0 +1,121 @@
+package inject
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	mapfake "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/fake"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecordInjection(t *testing.T) {
+	status := &v1alpha1.MapStatus{LastError: "previous error"}
+	now := metav1.NewTime(time.Now())
+
+	RecordInjection(status, 2, &model.Workload{Kind: "Deployment", Name: "envprinter"}, nil, now)
+	RecordInjection(status, 2, &model.Workload{Kind: "StatefulSet", Name: "database"}, nil, now)
+	RecordInjection(status, 3, &model.Workload{Kind: "Deployment", Name: "envprinter"}, nil, now)
+
+	expected := []v1alpha1.WorkloadReference{
+		{Kind: "StatefulSet", Name: "database"},
+		{Kind: "Deployment", Name: "envprinter"},
+	}
+	if fmt.Sprint(status.Workloads) != fmt.Sprint(expected) {
+		t.Errorf("Got unexpected workloads: %v", status.Workloads)
+	}
+	if status.LastError != "" {
+		t.Errorf("Got unexpected last error: %s", status.LastError)
+	}
+	if status.LastInjectionTime == nil || !status.LastInjectionTime.Equal(&now) {
+		t.Errorf("Got unexpected last injection time: %v", status.LastInjectionTime)
+	}
+	if status.ObservedGeneration != 3 {
+		t.Errorf("Got unexpected observed generation: %d", status.ObservedGeneration)
+	}
+}
+
+func TestRecordInjectionError(t *testing.T) {
+	status := &v1alpha1.MapStatus{}
+	RecordInjection(status, 1, &model.Workload{Kind: "Deployment", Name: "envprinter"}, errors.New("Vault sealed"), metav1.NewTime(time.Now()))
+
+	if status.LastError != "Vault sealed" {
+		t.Errorf("Got unexpected last error: %s", status.LastError)
+	}
+	if len(status.Workloads) != 0 || status.LastInjectionTime != nil {
+		t.Errorf("Got unexpected status for failed injection: %v", status)
+	}
+	if status.ObservedGeneration != 1 {
+		t.Errorf("Got unexpected observed generation: %d", status.ObservedGeneration)
+	}
+}
+
+func TestRecordInjectionLimit(t *testing.T) {
+	status := &v1alpha1.MapStatus{}
+	for i := 0; i < maxStatusWorkloads+5; i++ {
+		RecordInjection(status, 1, &model.Workload{Kind: "Deployment", Name: fmt.Sprintf("app%d", i)}, nil, metav1.NewTime(time.Now()))
+	}
+
+	if len(status.Workloads) != maxStatusWorkloads {
+		t.Fatalf("Got unexpected number of workloads: %d", len(status.Workloads))
+	}
+	if status.Workloads[0].Name != "app5" {
+		t.Errorf("Got unexpected oldest workload: %s", status.Workloads[0].Name)
+	}
+}
+
+func TestUpdateStatus(t *testing.T) {
+	vaultmap := testVaultMap("1")
+	vaultmap.Generation = 4
+	injector, _ := testInjector([]*v1alpha1.VaultMap{vaultmap}, nil)
+
+	injector.updateStatus(vaultmap, &model.Workload{Kind: "Deployment", Name: "envprinter"}, nil)
+	injector.updateStatus(vaultmap, &model.Workload{Kind: "StatefulSet", Name: "database"}, nil)
+	if actions := injector.mapclientset.(*mapfake.Clientset).Actions(); len(actions) != 0 {
+		t.Fatalf("Expected the status to be updated in the background but got actions: %v", actions)
+	}
+	if injector.status.queue.Len() != 1 {
+		t.Fatalf("Expected the status updates to be coalesced but got %d", injector.status.queue.Len())
+	}
+	injector.status.processNextItem()
+
+	updated, err := injector.mapclientset.VaultinitV1alpha1().VaultMaps("default").Get(vaultmap.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Getting vault map resulted in an error: %v", err)
+	}
+	expected := []v1alpha1.WorkloadReference{
+		{Kind: "Deployment", Name: "envprinter"},
+		{Kind: "StatefulSet", Name: "database"},
+	}
+	if fmt.Sprint(updated.Status.Workloads) != fmt.Sprint(expected) {
+		t.Errorf("Got unexpected workloads: %v", updated.Status.Workloads)
+	}
+	if updated.Status.ObservedGeneration != 4 {
+		t.Errorf("Got unexpected observed generation: %d", updated.Status.ObservedGeneration)
+	}
+
+	updates := 0
+	for _, action := range injector.mapclientset.(*mapfake.Clientset).Actions() {
+		if action.GetVerb() == "update" {
+			updates++
+		}
+	}
+	if updates != 1 {
+		t.Errorf("Got unexpected number of status updates: %d", updates)
+	}
+}
+
+func TestUpdateStatusClusterVaultMap(t *testing.T) {
+	injector, _ := testInjector(nil, nil)
+	clusterMap := ClusterVaultMaps([]*v1alpha1.ClusterVaultMap{{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}})[0]
+
+	workload, _ := deploymentContainer(testDeployment(nil))
+	injector.updateStatus(clusterMap, workload, nil)
+
+	if injector.status.queue.Len() != 0 {
+		t.Errorf("Got unexpected status update for cluster vault map")
+	}
+}
@@ -5,11 +5,36 @@ import (
 	"html/template"
 
 	"github.com/richardcase/vault-initializer/pkg/model"
-	"k8s.io/api/apps/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 )
 
-func ResolveTemplate(deployment *v1beta1.Deployment, pathTemplate string) (string, error) {
-	pc := model.PathConfig{Namespace: deployment.Namespace, DeploymentName: deployment.Name, ContainerName: deployment.Spec.Template.Spec.Containers[0].Name}
+// ResolveTemplate resolves a template for a container of a workload
+func ResolveTemplate(workload *model.Workload, container *corev1.Container, pathTemplate string) (string, error) {
+	pc := model.PathConfig{
+		Namespace:      workload.Namespace,
+		DeploymentName: workload.Name,
+		WorkloadName:   workload.Name,
+		WorkloadKind:   workload.Kind,
+		ContainerName:  container.Name,
+	}
+	return executeTemplate(pathTemplate, pc)
+}
+
+// ValidateTemplate checks that a template parses and only uses the fields
+// of the path config by resolving it for a sample container
+func ValidateTemplate(pathTemplate string) error {
+	pc := model.PathConfig{
+		Namespace:      "default",
+		DeploymentName: "workload",
+		WorkloadName:   "workload",
+		WorkloadKind:   "Deployment",
+		ContainerName:  "container",
+	}
+	_, err := executeTemplate(pathTemplate, pc)
+	return err
+}
+
+func executeTemplate(pathTemplate string, pc model.PathConfig) (string, error) {
 	tmpl, err := template.New("pathTemplate").Parse(pathTemplate)
 	if err != nil {
 		return "", err
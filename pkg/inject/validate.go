@@ -0,0 +1,121 @@
+package inject
+
+import (
+	"fmt"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1beta1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// specTemplate is a template in a vault map and the field it's in
+type specTemplate struct {
+	field    string
+	template string
+}
+
+// ValidateMapSpec checks that the templates of a vault map can be resolved
+// and that the volume publisher options are set if any of the paths use it.
+// All of the invalid fields are included in the error.
+func ValidateMapSpec(spec *v1alpha1.MapSpec) error {
+	var errs []error
+	if usesVolumePublisher(spec) {
+		for _, option := range []specTemplate{
+			{"spec.secretsFilePathPattern", spec.SecretsFilePathPattern},
+			{"spec.secretsFileNamePattern", spec.SecretsFileNamePattern},
+			{"spec.secretNamePattern", spec.SecretNamePattern},
+		} {
+			if option.template == "" {
+				errs = append(errs, fmt.Errorf("Missing %s, it is required for the volume publisher", option.field))
+			}
+		}
+	}
+
+	templates := []specTemplate{
+		{"spec.vaultPathPattern", spec.VaultPathPattern},
+		{"spec.secretsFilePathPattern", spec.SecretsFilePathPattern},
+		{"spec.secretsFileNamePattern", spec.SecretsFileNamePattern},
+		{"spec.secretNamePattern", spec.SecretNamePattern},
+	}
+	for i, path := range spec.VaultPaths {
+		templates = append(templates, specTemplate{fmt.Sprintf("spec.vaultPaths[%d].pathPattern", i), path.PathPattern})
+	}
+	if err := validateTemplates(templates); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// usesVolumePublisher returns true if the spec or any of its paths publish
+// secrets as a volume
+func usesVolumePublisher(spec *v1alpha1.MapSpec) bool {
+	if spec.SecretsPublisher == "volume" {
+		return true
+	}
+	for _, path := range spec.VaultPaths {
+		if path.SecretsPublisher == "volume" {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateV1beta1MapSpec checks that the templates of a v1beta1 vault map
+// can be resolved and that the volume publisher options are set if the map
+// or any of its paths use it
+func ValidateV1beta1MapSpec(spec *v1beta1.MapSpec) error {
+	var errs []error
+	volume := spec.Publisher.Volume
+	if volume == nil {
+		volume = &v1beta1.VolumePublisherSpec{}
+	}
+	if usesV1beta1VolumePublisher(spec) {
+		for _, option := range []specTemplate{
+			{"spec.publisher.volume.secretNamePattern", volume.SecretNamePattern},
+			{"spec.publisher.volume.filePathPattern", volume.FilePathPattern},
+			{"spec.publisher.volume.fileNamePattern", volume.FileNamePattern},
+		} {
+			if option.template == "" {
+				errs = append(errs, fmt.Errorf("Missing %s, it is required for the volume publisher", option.field))
+			}
+		}
+	}
+
+	var templates []specTemplate
+	for i, path := range spec.Paths {
+		templates = append(templates, specTemplate{fmt.Sprintf("spec.paths[%d].path", i), path.Path})
+	}
+	templates = append(templates,
+		specTemplate{"spec.publisher.volume.secretNamePattern", volume.SecretNamePattern},
+		specTemplate{"spec.publisher.volume.filePathPattern", volume.FilePathPattern},
+		specTemplate{"spec.publisher.volume.fileNamePattern", volume.FileNamePattern},
+	)
+	if err := validateTemplates(templates); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// usesV1beta1VolumePublisher returns true if the v1beta1 spec or any of its
+// paths publish secrets as a volume
+func usesV1beta1VolumePublisher(spec *v1beta1.MapSpec) bool {
+	if spec.Publisher.Type == "volume" {
+		return true
+	}
+	for _, path := range spec.Paths {
+		if path.Publisher == "volume" {
+			return true
+		}
+	}
+	return false
+}
+
+func validateTemplates(templates []specTemplate) error {
+	var errs []error
+	for _, t := range templates {
+		if err := ValidateTemplate(t.template); err != nil {
+			errs = append(errs, fmt.Errorf("Invalid template in %s: %v", t.field, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
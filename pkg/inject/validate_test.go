@@ -0,0 +1,158 @@
+package inject
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1beta1"
+)
+
+func TestValidateMapSpec(t *testing.T) {
+	vaultmap := testVaultMap("1")
+	vaultmap.Spec.SecretNamePattern = "{{.Namespace}}.{{.WorkloadKind}}.{{.ContainerName}}"
+	vaultmap.Spec.VaultPaths = []v1alpha1.VaultPath{{PathPattern: "/v1/secret/{{.WorkloadName}}"}}
+
+	if err := ValidateMapSpec(&vaultmap.Spec); err != nil {
+		t.Errorf("Validating vault map resulted in an error: %v", err)
+	}
+}
+
+func TestValidateMapSpecInvalidTemplates(t *testing.T) {
+	vaultmap := testVaultMap("1")
+	vaultmap.Spec.VaultPathPattern = "/v1/secret/{{.Namespace}/{{.ContainerName}}"
+	vaultmap.Spec.VaultPaths = []v1alpha1.VaultPath{
+		{PathPattern: "/v1/secret/shared"},
+		{PathPattern: "/v1/secret/{{.PodName}}"},
+	}
+
+	err := ValidateMapSpec(&vaultmap.Spec)
+	if err == nil {
+		t.Fatalf("Expected an error validating invalid templates")
+	}
+	for _, field := range []string{"spec.vaultPathPattern", "spec.vaultPaths[1].pathPattern"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Expected error to include %s: %v", field, err)
+		}
+	}
+	if strings.Contains(err.Error(), "spec.vaultPaths[0]") {
+		t.Errorf("Got unexpected error for valid path: %v", err)
+	}
+}
+
+func TestValidateMapSpecVolumePublisher(t *testing.T) {
+	// The volume options are only needed if a path uses the volume publisher
+	vaultmap := testVaultMap("1")
+	if err := ValidateMapSpec(&vaultmap.Spec); err != nil {
+		t.Errorf("Validating vault map resulted in an error: %v", err)
+	}
+
+	vaultmap.Spec.VaultPaths = []v1alpha1.VaultPath{{PathPattern: "/v1/secret/shared", SecretsPublisher: "volume"}}
+	vaultmap.Spec.SecretsFileNamePattern = "config.json"
+	err := ValidateMapSpec(&vaultmap.Spec)
+	if err == nil {
+		t.Fatalf("Expected an error validating a volume map without its options")
+	}
+	for _, field := range []string{"spec.secretsFilePathPattern", "spec.secretNamePattern"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Expected error to include %s: %v", field, err)
+		}
+	}
+	if strings.Contains(err.Error(), "spec.secretsFileNamePattern") {
+		t.Errorf("Got unexpected error for option that is set: %v", err)
+	}
+
+	if err := ValidateMapSpec(&volumeVaultMap().Spec); err != nil {
+		t.Errorf("Validating volume vault map resulted in an error: %v", err)
+	}
+}
+
+func TestValidateV1beta1MapSpec(t *testing.T) {
+	spec := &v1beta1.MapSpec{
+		Paths: []v1beta1.VaultPath{
+			{Path: "/v1/secret/{{.Namespace}}/{{.ContainerName}}"},
+			{Path: "/v1/secret/{{.Namespace}/shared"},
+		},
+		Publisher: v1beta1.PublisherSpec{
+			Type:   "volume",
+			Volume: &v1beta1.VolumePublisherSpec{SecretNamePattern: "{{.Secret}}", FileNamePattern: "config.json"},
+		},
+	}
+
+	err := ValidateV1beta1MapSpec(spec)
+	if err == nil {
+		t.Fatalf("Expected an error validating invalid templates")
+	}
+	for _, field := range []string{"spec.paths[1].path", "spec.publisher.volume.secretNamePattern"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Expected error to include %s: %v", field, err)
+		}
+	}
+	if strings.Contains(err.Error(), "spec.paths[0]") || strings.Contains(err.Error(), "fileNamePattern") {
+		t.Errorf("Got unexpected error for valid templates: %v", err)
+	}
+}
+
+func TestValidateV1beta1MapSpecVolumePublisher(t *testing.T) {
+	// The volume options are only needed if the map or a path uses the volume publisher
+	spec := &v1beta1.MapSpec{
+		Paths:     []v1beta1.VaultPath{{Path: "/v1/secret/{{.Namespace}}/{{.ContainerName}}"}},
+		Publisher: v1beta1.PublisherSpec{Type: "env"},
+	}
+	if err := ValidateV1beta1MapSpec(spec); err != nil {
+		t.Errorf("Validating vault map resulted in an error: %v", err)
+	}
+
+	spec.Paths = append(spec.Paths, v1beta1.VaultPath{Path: "/v1/secret/shared", Publisher: "volume"})
+	spec.Publisher.Volume = &v1beta1.VolumePublisherSpec{FileNamePattern: "config.json"}
+	err := ValidateV1beta1MapSpec(spec)
+	if err == nil {
+		t.Fatalf("Expected an error validating a volume map without its options")
+	}
+	for _, field := range []string{"spec.publisher.volume.filePathPattern", "spec.publisher.volume.secretNamePattern"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Expected error to include %s: %v", field, err)
+		}
+	}
+	if strings.Contains(err.Error(), "spec.publisher.volume.fileNamePattern") {
+		t.Errorf("Got unexpected error for option that is set: %v", err)
+	}
+
+	spec.Paths = spec.Paths[:1]
+	spec.Publisher = v1beta1.PublisherSpec{Type: "volume"}
+	err = ValidateV1beta1MapSpec(spec)
+	if err == nil || !strings.Contains(err.Error(), "spec.publisher.volume.fileNamePattern") {
+		t.Errorf("Expected an error validating a volume map without a volume section: %v", err)
+	}
+
+	spec.Publisher.Volume = &v1beta1.VolumePublisherSpec{
+		SecretNamePattern: "{{.Namespace}}.{{.WorkloadName}}.{{.ContainerName}}",
+		FilePathPattern:   "/etc/secrets",
+		FileNamePattern:   "config.json",
+	}
+	if err := ValidateV1beta1MapSpec(spec); err != nil {
+		t.Errorf("Validating volume vault map resulted in an error: %v", err)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		valid    bool
+	}{
+		{"/v1/secret/{{.Namespace}}/{{.ContainerName}}", true},
+		{"{{.DeploymentName}}-{{.WorkloadName}}-{{.WorkloadKind}}", true},
+		{"config.json", true},
+		{"", true},
+		{"{{.Namespace", false},
+		{"{{.Namespace}}/{{.Unknown}}", false},
+		{"{{ range }}", false},
+	}
+
+	for _, test := range tests {
+		err := ValidateTemplate(test.template)
+		if (err == nil) != test.valid {
+			t.Errorf("Got unexpected result validating %s: %v", test.template, err)
+		}
+	}
+}
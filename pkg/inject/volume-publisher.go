@@ -1,34 +1,48 @@
 package inject
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"log"
 	"path"
+	"strings"
 
 	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
-	"k8s.io/api/apps/v1beta1"
+	"github.com/richardcase/vault-initializer/pkg/model"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	// ManagedByLabel marks the secrets created by the volume publisher
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// WorkloadAnnotation records the workload that a secret created by the
+	// volume publisher belongs to
+	WorkloadAnnotation = "vault.initializer/workload"
+
+	managedBy = "vault-initializer"
+)
+
 // VolumePublisher is a secrets publisher that makes secrets available as a volume
 type VolumePublisher struct{}
 
 // PublishSecrets publishes secrets as a volume.
-func (p VolumePublisher) PublishSecrets(vaultmap *v1alpha1.VaultMap, clientset *kubernetes.Clientset, deployment *v1beta1.Deployment, secrets map[string]string) error {
-	namespace := deployment.Namespace
+func (p VolumePublisher) PublishSecrets(vaultmap *v1alpha1.VaultMap, clientset kubernetes.Interface, workload *model.Workload, container *corev1.Container, secrets map[string]string) error {
+	namespace := workload.Namespace
 
 	// Resolve templates
-	secretName, err := ResolveTemplate(deployment, vaultmap.Spec.SecretNamePattern)
+	secretName, err := ResolveTemplate(workload, container, vaultmap.Spec.SecretNamePattern)
 	if err != nil {
 		return err
 	}
-	secretFilePath, err := ResolveTemplate(deployment, vaultmap.Spec.SecretsFilePathPattern)
+	secretFilePath, err := ResolveTemplate(workload, container, vaultmap.Spec.SecretsFilePathPattern)
 	if err != nil {
 		return err
 	}
-	secretFileName, err := ResolveTemplate(deployment, vaultmap.Spec.SecretsFileNamePattern)
+	secretFileName, err := ResolveTemplate(workload, container, vaultmap.Spec.SecretsFileNamePattern)
 	if err != nil {
 		return err
 	}
@@ -49,50 +63,105 @@ func (p VolumePublisher) PublishSecrets(vaultmap *v1alpha1.VaultMap, clientset *
 	secret.Data[secretFileName] = []byte(jsonSecrets)
 	secret.Type = corev1.SecretTypeOpaque
 	secret.Name = secretName
-
-	exists, err := secretExists(clientset, namespace, secretName)
-	if err != nil {
-		return err
-	}
-
-	if exists {
-		log.Printf("Secret %s already exists in namespace %s", secretName, namespace)
-	} else {
-		log.Printf("Creating secret %s in namespace %s", secretName, namespace)
-		_, err = clientset.CoreV1().Secrets(namespace).Create(&secret)
+	secret.Labels = map[string]string{ManagedByLabel: managedBy}
+	secret.Annotations = map[string]string{WorkloadAnnotation: workload.Kind + "/" + workload.Name}
+
+	// Containers whose secret names resolve to the same secret share its
+	// volume, which only works if their data agrees
+	volumeName := secretsVolumeName(container)
+	shared := sharedSecretVolume(workload, secretName)
+	if shared != nil {
+		existing, err := clientset.CoreV1().Secrets(namespace).Get(secretName, metav1.GetOptions{})
 		if err != nil {
 			return err
 		}
+		if data, ok := existing.Data[secretFileName]; ok && !bytes.Equal(data, secret.Data[secretFileName]) {
+			return fmt.Errorf("Secret %s for container %s conflicts with the secret of another container, the secret name pattern needs to include {{.ContainerName}}", secretName, container.Name)
+		}
+		volumeName = shared.Name
+	}
+
+	if err = applySecret(clientset, namespace, &secret); err != nil {
+		return err
 	}
 
 	// Create volume pointing to secrets
-	volume := corev1.Volume{}
-	volume.Name = "secrets"
-	volume.Secret = &corev1.SecretVolumeSource{SecretName: secretName}
-	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
+	if shared == nil {
+		volume := corev1.Volume{}
+		volume.Name = volumeName
+		volume.Secret = &corev1.SecretVolumeSource{SecretName: secretName}
+		workload.Template.Spec.Volumes = append(workload.Template.Spec.Volumes, volume)
+	}
 
 	// Add volume to container
 
 	mount := corev1.VolumeMount{}
-	mount.Name = "secrets"
+	mount.Name = volumeName
 	mount.MountPath = secretFullPath
 	mount.ReadOnly = true
 	mount.SubPath = secretFileName
-	deployment.Spec.Template.Spec.Containers[0].VolumeMounts = append(deployment.Spec.Template.Spec.Containers[0].VolumeMounts, mount)
+	container.VolumeMounts = append(container.VolumeMounts, mount)
+
+	return nil
+}
 
+// secretsVolumeName returns the name of the secrets volume for a container,
+// which needs to be a valid DNS label
+func secretsVolumeName(container *corev1.Container) string {
+	name := "secrets-" + container.Name
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	return name
+}
+
+// sharedSecretVolume returns the secrets volume that another container of
+// the workload has for a secret, nil if there isn't one
+func sharedSecretVolume(workload *model.Workload, secretName string) *corev1.Volume {
+	for i, volume := range workload.Template.Spec.Volumes {
+		if strings.HasPrefix(volume.Name, "secrets-") && volume.Secret != nil && volume.Secret.SecretName == secretName {
+			return &workload.Template.Spec.Volumes[i]
+		}
+	}
 	return nil
 }
 
-func secretExists(clientset *kubernetes.Clientset, namespace string, secretName string) (bool, error) {
-	secretsList, err := clientset.CoreV1().Secrets(namespace).List(metav1.ListOptions{})
+// applySecret creates a secret, or updates the data of the secret if it
+// already exists so that the secrets of a pinned version are published. Only
+// secrets that were created for the same workload are updated, so workloads
+// whose secret names resolve to the same secret don't overwrite each other's
+// secrets or ones that were created by users.
+func applySecret(clientset kubernetes.Interface, namespace string, secret *corev1.Secret) error {
+	existing, err := clientset.CoreV1().Secrets(namespace).Get(secret.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		log.Printf("Creating secret %s in namespace %s", secret.Name, namespace)
+		_, err = clientset.CoreV1().Secrets(namespace).Create(secret)
+		return err
+	}
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	for _, secret := range secretsList.Items {
-		if secret.ObjectMeta.Namespace == namespace && secret.ObjectMeta.Name == secretName {
-			return true, nil
+	changed := false
+	if existing.Data == nil {
+		existing.Data = make(map[string][]byte)
+	}
+	for key, value := range secret.Data {
+		if !bytes.Equal(existing.Data[key], value) {
+			existing.Data[key] = value
+			changed = true
 		}
 	}
-	return false, nil
+	if !changed {
+		log.Printf("Secret %s already exists in namespace %s", secret.Name, namespace)
+		return nil
+	}
+	owner := secret.Annotations[WorkloadAnnotation]
+	if existing.Labels[ManagedByLabel] != managedBy || existing.Annotations[WorkloadAnnotation] != owner {
+		return fmt.Errorf("Secret %s in namespace %s already exists and wasn't created for %s, the secret name pattern needs to include {{.WorkloadName}}", secret.Name, namespace, owner)
+	}
+
+	log.Printf("Updating secret %s in namespace %s", secret.Name, namespace)
+	_, err = clientset.CoreV1().Secrets(namespace).Update(existing)
+	return err
 }
@@ -0,0 +1,240 @@
+package inject
+
+import (
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestVolumePublisherUpdatesSecret(t *testing.T) {
+	clientset := fake.NewSimpleClientset(managedSecret("Deployment/envprinter", `{"mysecret":"NewPassword"}`))
+
+	workload, container := deploymentContainer(testDeployment(map[string]string{VersionAnnotation: "2"}))
+	err := VolumePublisher{}.PublishSecrets(volumeVaultMap(), clientset, workload, container, map[string]string{"mysecret": "OldPassword"})
+	if err != nil {
+		t.Fatalf("Publishing secrets resulted in an error: %v", err)
+	}
+
+	assertSecretData(t, clientset, "default.envprinter", map[string]string{"config.json": `{"mysecret":"OldPassword"}`})
+}
+
+func TestVolumePublisherSecretOwnership(t *testing.T) {
+	tests := []struct {
+		secret *corev1.Secret
+		err    bool
+	}{
+		// A secret created by a user
+		{secret: managedSecret("", `{"mysecret":"UserPassword"}`), err: true},
+		// A secret created for another workload
+		{secret: managedSecret("Deployment/other", `{"mysecret":"OtherPassword"}`), err: true},
+		// Secrets that don't need to change are used as they are
+		{secret: managedSecret("", `{"mysecret":"Password123"}`), err: false},
+	}
+
+	for _, test := range tests {
+		expected := string(test.secret.Data["config.json"])
+		clientset := fake.NewSimpleClientset(test.secret)
+
+		workload, container := deploymentContainer(testDeployment(nil))
+		err := VolumePublisher{}.PublishSecrets(volumeVaultMap(), clientset, workload, container, map[string]string{"mysecret": "Password123"})
+		if test.err && err == nil {
+			t.Errorf("Publishing secrets over %v resulted in no error where an error was expected", test.secret.ObjectMeta)
+		}
+		if !test.err && err != nil {
+			t.Errorf("Publishing secrets over %v resulted in an error: %v", test.secret.ObjectMeta, err)
+		}
+		assertSecretData(t, clientset, "default.envprinter", map[string]string{"config.json": expected})
+	}
+}
+
+func TestVolumePublisherSecretMetadata(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	workload, container := deploymentContainer(testDeployment(nil))
+	if err := (VolumePublisher{}).PublishSecrets(volumeVaultMap(), clientset, workload, container, map[string]string{"mysecret": "Password123"}); err != nil {
+		t.Fatalf("Publishing secrets resulted in an error: %v", err)
+	}
+
+	secret, err := clientset.CoreV1().Secrets("default").Get("default.envprinter", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Getting secret resulted in an error: %v", err)
+	}
+	if secret.Labels[ManagedByLabel] != "vault-initializer" || secret.Annotations[WorkloadAnnotation] != "Deployment/envprinter" {
+		t.Errorf("Got unexpected secret metadata: %v", secret.ObjectMeta)
+	}
+}
+
+func TestVolumePublisherPerContainer(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	deployment := testDeployment(nil)
+	containers := &deployment.Spec.Template.Spec.Containers
+	*containers = append(*containers, corev1.Container{Name: "sidecar"})
+	workload := DeploymentWorkload(deployment)
+
+	publisher := VolumePublisher{}
+	if err := publisher.PublishSecrets(volumeVaultMap(), clientset, workload, &(*containers)[0], map[string]string{"mysecret": "Password123"}); err != nil {
+		t.Fatalf("Publishing secrets resulted in an error: %v", err)
+	}
+	if err := publisher.PublishSecrets(volumeVaultMap(), clientset, workload, &(*containers)[1], map[string]string{"othersecret": "Password456"}); err != nil {
+		t.Fatalf("Publishing secrets resulted in an error: %v", err)
+	}
+
+	volumes := workload.Template.Spec.Volumes
+	if len(volumes) != 2 {
+		t.Fatalf("Got unexpected number of volumes: %d", len(volumes))
+	}
+	assertVolume(t, volumes[0], "secrets-envprinter", "default.envprinter")
+	assertVolume(t, volumes[1], "secrets-sidecar", "default.sidecar")
+	assertMount(t, &(*containers)[0], "secrets-envprinter")
+	assertMount(t, &(*containers)[1], "secrets-sidecar")
+	assertSecretData(t, clientset, "default.envprinter", map[string]string{"config.json": `{"mysecret":"Password123"}`})
+	assertSecretData(t, clientset, "default.sidecar", map[string]string{"config.json": `{"othersecret":"Password456"}`})
+}
+
+func TestVolumePublisherSharedSecret(t *testing.T) {
+	tests := []struct {
+		fileNamePattern string
+		secrets         []map[string]string
+		expected        map[string]string
+		err             bool
+	}{
+		{
+			fileNamePattern: "config.json",
+			secrets:         []map[string]string{{"mysecret": "Password123"}, {"mysecret": "Password123"}},
+			expected:        map[string]string{"config.json": `{"mysecret":"Password123"}`},
+		},
+		{
+			fileNamePattern: "{{.ContainerName}}.json",
+			secrets:         []map[string]string{{"mysecret": "Password123"}, {"othersecret": "Password456"}},
+			expected:        map[string]string{"envprinter.json": `{"mysecret":"Password123"}`, "sidecar.json": `{"othersecret":"Password456"}`},
+		},
+		{
+			fileNamePattern: "config.json",
+			secrets:         []map[string]string{{"mysecret": "Password123"}, {"othersecret": "Password456"}},
+			err:             true,
+		},
+	}
+
+	for _, test := range tests {
+		clientset := fake.NewSimpleClientset()
+		vaultmap := volumeVaultMap()
+		vaultmap.Spec.SecretNamePattern = "{{.Namespace}}.{{.DeploymentName}}"
+		vaultmap.Spec.SecretsFileNamePattern = test.fileNamePattern
+		deployment := testDeployment(nil)
+		containers := &deployment.Spec.Template.Spec.Containers
+		*containers = append(*containers, corev1.Container{Name: "sidecar"})
+		workload := DeploymentWorkload(deployment)
+
+		var err error
+		for i, secrets := range test.secrets {
+			if err = (VolumePublisher{}).PublishSecrets(vaultmap, clientset, workload, &(*containers)[i], secrets); err != nil {
+				break
+			}
+		}
+		if test.err {
+			if err == nil {
+				t.Errorf("Publishing secrets %v resulted in no error where an error was expected", test.secrets)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Publishing secrets %v resulted in an error: %v", test.secrets, err)
+		}
+
+		volumes := workload.Template.Spec.Volumes
+		if len(volumes) != 1 {
+			t.Fatalf("Got unexpected number of volumes: %d", len(volumes))
+		}
+		assertVolume(t, volumes[0], "secrets-envprinter", "default.envprinter")
+		assertMount(t, &(*containers)[0], "secrets-envprinter")
+		assertMount(t, &(*containers)[1], "secrets-envprinter")
+		assertSecretData(t, clientset, "default.envprinter", test.expected)
+	}
+}
+
+func TestVolumePublisherInitContainers(t *testing.T) {
+	for _, initContainers := range []bool{true, false} {
+		clientset := fake.NewSimpleClientset()
+		vaultmap := volumeVaultMap()
+		vaultmap.Spec.InjectInitContainers = initContainers
+		deployment := testDeployment(nil)
+		deployment.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "migrate"}}
+		workload := DeploymentWorkload(deployment)
+
+		publishToTargets(t, VolumePublisher{}, vaultmap, clientset, workload, map[string]string{"mysecret": "Password123"})
+
+		volumes := workload.Template.Spec.Volumes
+		initContainer := &deployment.Spec.Template.Spec.InitContainers[0]
+		assertMount(t, &deployment.Spec.Template.Spec.Containers[0], "secrets-envprinter")
+		if !initContainers {
+			if len(volumes) != 1 || len(initContainer.VolumeMounts) != 0 {
+				t.Errorf("Expected init container to be left untouched: %v %v", volumes, initContainer.VolumeMounts)
+			}
+			if _, err := clientset.CoreV1().Secrets("default").Get("default.migrate", metav1.GetOptions{}); err == nil {
+				t.Error("Expected no secret for the init container")
+			}
+			continue
+		}
+
+		if len(volumes) != 2 {
+			t.Fatalf("Got unexpected number of volumes: %d", len(volumes))
+		}
+		assertVolume(t, volumes[0], "secrets-migrate", "default.migrate")
+		assertMount(t, initContainer, "secrets-migrate")
+		assertSecretData(t, clientset, "default.migrate", map[string]string{"config.json": `{"mysecret":"Password123"}`})
+	}
+}
+
+// managedSecret returns a secret created by the volume publisher for a
+// workload, or by a user if the workload is empty
+func managedSecret(workload string, data string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "default.envprinter"},
+		Data:       map[string][]byte{"config.json": []byte(data)},
+	}
+	if workload != "" {
+		secret.Labels = map[string]string{ManagedByLabel: "vault-initializer"}
+		secret.Annotations = map[string]string{WorkloadAnnotation: workload}
+	}
+	return secret
+}
+
+// volumeVaultMap returns a vault map that publishes secrets as a volume
+func volumeVaultMap() *v1alpha1.VaultMap {
+	vaultmap := testVaultMap("1")
+	vaultmap.Spec.SecretsPublisher = "volume"
+	vaultmap.Spec.SecretNamePattern = "{{.Namespace}}.{{.ContainerName}}"
+	vaultmap.Spec.SecretsFilePathPattern = "/"
+	vaultmap.Spec.SecretsFileNamePattern = "config.json"
+	return vaultmap
+}
+
+func assertSecretData(t *testing.T, clientset *fake.Clientset, name string, expected map[string]string) {
+	secret, err := clientset.CoreV1().Secrets("default").Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Getting secret %s resulted in an error: %v", name, err)
+	}
+	if len(secret.Data) != len(expected) {
+		t.Errorf("Got unexpected data in secret %s: %v", name, secret.Data)
+	}
+	for key, value := range expected {
+		if string(secret.Data[key]) != value {
+			t.Errorf("Got unexpected value for %s in secret %s: %s", key, name, string(secret.Data[key]))
+		}
+	}
+}
+
+func assertVolume(t *testing.T, volume corev1.Volume, name string, secretName string) {
+	if volume.Name != name || volume.Secret == nil || volume.Secret.SecretName != secretName {
+		t.Errorf("Got unexpected volume: %v", volume)
+	}
+}
+
+func assertMount(t *testing.T, container *corev1.Container, volumeName string) {
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].Name != volumeName {
+		t.Errorf("Got unexpected volume mounts in container %s: %v", container.Name, container.VolumeMounts)
+	}
+}
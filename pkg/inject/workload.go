@@ -0,0 +1,86 @@
+package inject
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/richardcase/vault-initializer/pkg/model"
+	"k8s.io/api/apps/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NewWorkload returns the workload for a resource with a pod template. The
+// workload refers to the pod template of the resource so injecting secrets
+// into the workload modifies the resource.
+func NewWorkload(obj runtime.Object) (*model.Workload, error) {
+	switch o := obj.(type) {
+	case *v1beta1.Deployment:
+		return DeploymentWorkload(o), nil
+	case *v1beta1.StatefulSet:
+		return newWorkload("StatefulSet", o, o.ObjectMeta, &o.Spec.Template), nil
+	case *extensionsv1beta1.DaemonSet:
+		return newWorkload("DaemonSet", o, o.ObjectMeta, &o.Spec.Template), nil
+	case *batchv1.Job:
+		return newWorkload("Job", o, o.ObjectMeta, &o.Spec.Template), nil
+	default:
+		return nil, fmt.Errorf("Unsupported workload type %T", obj)
+	}
+}
+
+// DeploymentWorkload returns the workload for a deployment. The workload
+// refers to the pod template of the deployment so injecting secrets into the
+// workload modifies the deployment.
+func DeploymentWorkload(deployment *v1beta1.Deployment) *model.Workload {
+	return newWorkload("Deployment", deployment, deployment.ObjectMeta, &deployment.Spec.Template)
+}
+
+func newWorkload(kind string, obj runtime.Object, meta metav1.ObjectMeta, template *corev1.PodTemplateSpec) *model.Workload {
+	return &model.Workload{
+		Kind:        kind,
+		Namespace:   meta.Namespace,
+		Name:        meta.Name,
+		Annotations: meta.Annotations,
+		Template:    template,
+		Object:      obj,
+	}
+}
+
+// PodWorkload returns the workload for a pod. Pods don't have a pod template
+// so the workload has a template made from the pod, which the pod needs to
+// be updated from after injecting secrets. The name of the workload is the
+// name of the controller that owns the pod, if there is one.
+func PodWorkload(pod *corev1.Pod) *model.Workload {
+	return &model.Workload{
+		Kind:        "Pod",
+		Namespace:   pod.Namespace,
+		Name:        podWorkloadName(pod),
+		Annotations: pod.Annotations,
+		Template: &corev1.PodTemplateSpec{
+			ObjectMeta: *pod.ObjectMeta.DeepCopy(),
+			Spec:       *pod.Spec.DeepCopy(),
+		},
+		Object: pod,
+	}
+}
+
+func podWorkloadName(pod *corev1.Pod) string {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Controller == nil || !*owner.Controller {
+			continue
+		}
+		// Pods of a deployment are owned by a replica set named after the
+		// deployment with the pod template hash appended
+		if hash, ok := pod.Labels["pod-template-hash"]; ok && owner.Kind == "ReplicaSet" {
+			return strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+		return owner.Name
+	}
+	if pod.Name != "" {
+		return pod.Name
+	}
+	return strings.TrimSuffix(pod.GenerateName, "-")
+}
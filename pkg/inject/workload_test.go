@@ -0,0 +1,108 @@
+package inject
+
+import (
+	"testing"
+
+	"k8s.io/api/apps/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestNewWorkload(t *testing.T) {
+	meta := metav1.ObjectMeta{Namespace: "default", Name: "envprinter"}
+	tests := []struct {
+		kind string
+		obj  runtime.Object
+	}{
+		{kind: "Deployment", obj: &v1beta1.Deployment{ObjectMeta: meta}},
+		{kind: "StatefulSet", obj: &v1beta1.StatefulSet{ObjectMeta: meta}},
+		{kind: "DaemonSet", obj: &extensionsv1beta1.DaemonSet{ObjectMeta: meta}},
+		{kind: "Job", obj: &batchv1.Job{ObjectMeta: meta}},
+	}
+
+	for _, test := range tests {
+		workload, err := NewWorkload(test.obj)
+		if err != nil {
+			t.Fatalf("%s: Creating workload resulted in an error: %v", test.kind, err)
+		}
+		if workload.Kind != test.kind || workload.Namespace != "default" || workload.Name != "envprinter" {
+			t.Errorf("%s: Got unexpected workload: %v", test.kind, workload)
+		}
+
+		// Changes to the workload template should change the object
+		workload.Template.Spec.ServiceAccountName = "vault"
+		if other, _ := NewWorkload(test.obj); other.Template.Spec.ServiceAccountName != "vault" {
+			t.Errorf("%s: Expected workload template to refer to the object", test.kind)
+		}
+	}
+}
+
+func TestNewWorkloadUnsupported(t *testing.T) {
+	if _, err := NewWorkload(&corev1.Service{}); err == nil {
+		t.Error("Creating workload resulted in no error where an error was expected")
+	}
+}
+
+func TestPodWorkloadName(t *testing.T) {
+	controller := true
+	tests := []struct {
+		name     string
+		meta     metav1.ObjectMeta
+		expected string
+	}{
+		{
+			name:     "named pod",
+			meta:     metav1.ObjectMeta{Name: "envprinter"},
+			expected: "envprinter",
+		},
+		{
+			name:     "generated name",
+			meta:     metav1.ObjectMeta{GenerateName: "envprinter-"},
+			expected: "envprinter",
+		},
+		{
+			name: "owned by statefulset",
+			meta: metav1.ObjectMeta{
+				Name: "envprinter-0",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "StatefulSet", Name: "envprinter", Controller: &controller},
+				},
+			},
+			expected: "envprinter",
+		},
+		{
+			name: "owned by deployment replica set",
+			meta: metav1.ObjectMeta{
+				GenerateName: "envprinter-5d4f8b9c7-",
+				Labels:       map[string]string{"pod-template-hash": "5d4f8b9c7"},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ReplicaSet", Name: "envprinter-5d4f8b9c7", Controller: &controller},
+				},
+			},
+			expected: "envprinter",
+		},
+		{
+			name: "non-controller owner",
+			meta: metav1.ObjectMeta{
+				Name: "envprinter",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ConfigMap", Name: "config"},
+				},
+			},
+			expected: "envprinter",
+		},
+	}
+
+	for _, test := range tests {
+		workload := PodWorkload(&corev1.Pod{ObjectMeta: test.meta})
+		if workload.Name != test.expected {
+			t.Errorf("%s: Got unexpected workload name: %s", test.name, workload.Name)
+		}
+		if workload.Kind != "Pod" {
+			t.Errorf("%s: Got unexpected workload kind: %s", test.name, workload.Kind)
+		}
+	}
+}
@@ -1,15 +1,33 @@
 package model
 
+import "time"
+
+const (
+	// FailurePolicyFail leaves workloads that secrets couldn't be injected into uninitialized
+	FailurePolicyFail = "Fail"
+	// FailurePolicyIgnore lets workloads that secrets couldn't be injected into start without them
+	FailurePolicyIgnore = "Ignore"
+)
+
 // Config represents the configuration of the initilaizer
 type Config struct {
-	RequireAnnotation      bool   `yaml:"requireAnnotation"`
-	AnnotatioName          string `yaml:"annotationName"`
-	IgnoreSystemNamespaces bool   `yaml:"ignoreSystemNamespaces"`
-	VaultAuthMode          string `yaml:"vaultAuthMode"` //TODO: enum??
-	VaultAddress           string `yaml:"vaultAddress"`
-	VaultPathPattern       string `yaml:"vaultPathPattern"`
-	SecretsPublisher       string `yaml:"secretsPublisher"`
-	SecretsFilePathPattern string `yaml:"secretsFilePathPattern"`
-	SecretsFileNamePattern string `yaml:"secretsFileNamePattern"`
-	SecretNamePattern      string `yaml:"secretNamePattern"`
+	RequireAnnotation       bool          `yaml:"requireAnnotation"`
+	AnnotatioName           string        `yaml:"annotationName"`
+	IgnoreSystemNamespaces  bool          `yaml:"ignoreSystemNamespaces"`
+	VaultAuthMode           string        `yaml:"vaultAuthMode"` //TODO: enum??
+	VaultAuthPath           string        `yaml:"vaultAuthPath"`
+	VaultRole               string        `yaml:"vaultRole"`
+	ServiceAccountTokenPath string        `yaml:"serviceAccountTokenPath"`
+	VaultAddress            string        `yaml:"vaultAddress"`
+	VaultTimeout            time.Duration `yaml:"vaultTimeout"`
+	VaultMaxRetries         int           `yaml:"vaultMaxRetries"`
+	VaultTLSServerName      string        `yaml:"vaultTLSServerName"`
+	VaultTLSSkipVerify      bool          `yaml:"vaultTLSSkipVerify"`
+	VaultPathPattern        string        `yaml:"vaultPathPattern"`
+	SecretsPublisher        string        `yaml:"secretsPublisher"`
+	SecretsFilePathPattern  string        `yaml:"secretsFilePathPattern"`
+	SecretsFileNamePattern  string        `yaml:"secretsFileNamePattern"`
+	SecretNamePattern       string        `yaml:"secretNamePattern"`
+	MaxRetries              int           `yaml:"maxRetries"`
+	FailurePolicy           string        `yaml:"failurePolicy"`
 }
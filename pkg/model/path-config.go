@@ -2,7 +2,11 @@ package model
 
 // PathConfig represents the options available for the vault path template
 type PathConfig struct {
-	Namespace      string
-	ContainerName  string
+	Namespace     string
+	ContainerName string
+	// DeploymentName is the name of the workload, it is kept for
+	// compatibility with templates written before other workloads were supported
 	DeploymentName string
+	WorkloadName   string
+	WorkloadKind   string
 }
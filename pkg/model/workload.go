@@ -0,0 +1,18 @@
+package model
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Workload represents a resource with a pod template that secrets are
+// injected into, such as a Deployment or a Pod
+type Workload struct {
+	Kind        string
+	Namespace   string
+	Name        string
+	Annotations map[string]string
+	Template    *corev1.PodTemplateSpec
+	// Object is the resource of the workload, events are recorded against it
+	Object runtime.Object
+}
@@ -0,0 +1,29 @@
+package vaultclient
+
+import (
+	"path"
+
+	"github.com/golang/glog"
+	vault "github.com/hashicorp/vault/api"
+)
+
+// AppRoleAuthenticator is a vault authenticator that logs in using an
+// AppRole role id and secret id
+type AppRoleAuthenticator struct {
+	MountPath string
+	RoleID    string
+	SecretID  string
+}
+
+// Login logs in to vault using the approle auth method
+func (a *AppRoleAuthenticator) Login(client *vault.Client) (*vault.SecretAuth, error) {
+	glog.V(2).Infof("Logging in to vault using approle auth with role id %s", a.RoleID)
+	data := map[string]interface{}{
+		"role_id": a.RoleID,
+	}
+	// The secret id is optional as a role may be configured without bind_secret_id
+	if a.SecretID != "" {
+		data["secret_id"] = a.SecretID
+	}
+	return login(client, path.Join("auth", a.MountPath, "login"), data)
+}
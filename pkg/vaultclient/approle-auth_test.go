@@ -0,0 +1,73 @@
+package vaultclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/model"
+)
+
+func TestAppRoleLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/auth/approle/login" {
+			t.Errorf("Got unexpected login path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Decoding login request resulted in an error: %v", err)
+		}
+		if body["role_id"] != "arole" {
+			t.Errorf("Got unexpected role_id: %s", body["role_id"])
+		}
+		if body["secret_id"] != "asecret" {
+			t.Errorf("Got unexpected secret_id: %s", body["secret_id"])
+		}
+		w.Write([]byte(`{"auth": {"client_token": "approletoken", "lease_duration": 1200, "renewable": true}}`))
+	}))
+	defer server.Close()
+
+	config := &model.Config{VaultAuthMode: "AppRole"}
+	secrets := map[string]string{"role_id": "arole", "secret_id": "asecret"}
+	authenticator, err := CreateAuthenticator(config, secrets)
+	if err != nil {
+		t.Fatalf("Creating authenticator resulted in an error: %v", err)
+	}
+
+	auth, err := authenticator.Login(testClient(t, server.URL))
+	if err != nil {
+		t.Fatalf("Logging in resulted in an error: %v", err)
+	}
+	if auth.ClientToken != "approletoken" {
+		t.Errorf("Got unexpected client token: %s", auth.ClientToken)
+	}
+}
+
+func TestAppRoleLoginDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errors": ["invalid secret id"]}`))
+	}))
+	defer server.Close()
+
+	config := &model.Config{VaultAuthMode: "AppRole"}
+	secrets := map[string]string{"role_id": "arole", "secret_id": "wrong"}
+	authenticator, err := CreateAuthenticator(config, secrets)
+	if err != nil {
+		t.Fatalf("Creating authenticator resulted in an error: %v", err)
+	}
+
+	if _, err = authenticator.Login(testClient(t, server.URL)); err == nil {
+		t.Error("Logging in resulted in no error where an error was expected")
+	}
+}
+
+func TestAppRoleAuthRequiresRoleID(t *testing.T) {
+	config := &model.Config{VaultAuthMode: "AppRole"}
+	if _, err := CreateAuthenticator(config, map[string]string{"secret_id": "asecret"}); err == nil {
+		t.Error("Creating authenticator resulted in no error where an error was expected")
+	}
+}
@@ -0,0 +1,156 @@
+package vaultclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang/glog"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/richardcase/vault-initializer/pkg/model"
+)
+
+const (
+	defaultKubernetesAuthPath      = "kubernetes"
+	defaultAppRoleAuthPath         = "approle"
+	defaultCertAuthPath            = "cert"
+	defaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// Authenticator is an interface that defines what vault auth methods need to implement.
+type Authenticator interface {
+	Login(client *vault.Client) (*vault.SecretAuth, error)
+}
+
+// CreateAuthenticator creates a new vault authenticator for the configured auth mode
+func CreateAuthenticator(config *model.Config, secrets map[string]string) (Authenticator, error) {
+	switch config.VaultAuthMode {
+	case "", "Token":
+		return &TokenAuthenticator{Token: secrets["vaultToken"]}, nil
+	case "Kubernetes":
+		if config.VaultRole == "" {
+			return nil, errors.New("vaultRole must be set when using the Kubernetes auth mode")
+		}
+		return &KubernetesAuthenticator{
+			MountPath: valueOrDefault(config.VaultAuthPath, defaultKubernetesAuthPath),
+			Role:      config.VaultRole,
+			TokenPath: valueOrDefault(config.ServiceAccountTokenPath, defaultServiceAccountTokenPath),
+		}, nil
+	case "AppRole":
+		if secrets["role_id"] == "" {
+			return nil, errors.New("role_id must be set in the initializer secret when using the AppRole auth mode")
+		}
+		return &AppRoleAuthenticator{
+			MountPath: valueOrDefault(config.VaultAuthPath, defaultAppRoleAuthPath),
+			RoleID:    secrets["role_id"],
+			SecretID:  secrets["secret_id"],
+		}, nil
+	case "Cert":
+		if secrets[clientCertSecretKey] == "" || secrets[clientKeySecretKey] == "" {
+			return nil, errors.New(clientCertSecretKey + " and " + clientKeySecretKey + " must be set in the initializer secret when using the Cert auth mode")
+		}
+		return &CertAuthenticator{
+			MountPath: valueOrDefault(config.VaultAuthPath, defaultCertAuthPath),
+			Role:      config.VaultRole,
+		}, nil
+	default:
+		return nil, fmt.Errorf("Invalid Vault Auth Mode: %s", config.VaultAuthMode)
+	}
+}
+
+// TokenAuthenticator is a vault authenticator that uses a static token
+type TokenAuthenticator struct {
+	Token string
+}
+
+// Login returns the static token along with its TTL. If no token has been
+// supplied the token the client was configured with (i.e. from VAULT_TOKEN) is used.
+func (a *TokenAuthenticator) Login(client *vault.Client) (*vault.SecretAuth, error) {
+	token := a.Token
+	if token == "" {
+		token = client.Token()
+	}
+	if token == "" {
+		return nil, errors.New("No vault token supplied")
+	}
+
+	auth := &vault.SecretAuth{ClientToken: token}
+	if err := lookupToken(client, auth); err != nil {
+		// The token may not be allowed to look itself up, in which case treat it as non-expiring
+		glog.Warningf("Unable to lookup vault token, it will not be renewed: %v", err)
+	}
+	return auth, nil
+}
+
+// lookupToken populates the TTL and renewable flag of a token from lookup-self
+func lookupToken(client *vault.Client, auth *vault.SecretAuth) error {
+	request := client.NewRequest("GET", "/v1/auth/token/lookup-self")
+	request.ClientToken = auth.ClientToken
+
+	resp, err := client.RawRequest(request)
+	if resp != nil && resp.Body != nil {
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+	}
+	if err != nil {
+		return err
+	}
+
+	secret, err := vault.ParseSecret(resp.Body)
+	if err != nil {
+		return err
+	}
+	if secret == nil || secret.Data == nil {
+		return errors.New("No data returned from token lookup")
+	}
+
+	if ttl, ok := secret.Data["ttl"].(json.Number); ok {
+		seconds, err := ttl.Int64()
+		if err != nil {
+			return err
+		}
+		auth.LeaseDuration = int(seconds)
+	}
+	if renewable, ok := secret.Data["renewable"].(bool); ok {
+		auth.Renewable = renewable
+	}
+	return nil
+}
+
+// login exchanges the supplied credentials for a client token at the
+// login endpoint of an auth method
+func login(client *vault.Client, loginPath string, data map[string]interface{}) (*vault.SecretAuth, error) {
+	request := client.NewRequest("PUT", "/v1/"+loginPath)
+	request.ClientToken = ""
+	if err := request.SetJSONBody(data); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.RawRequest(request)
+	if resp != nil && resp.Body != nil {
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := vault.ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, fmt.Errorf("No auth information returned from %s", loginPath)
+	}
+
+	return secret.Auth, nil
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
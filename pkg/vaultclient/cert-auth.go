@@ -0,0 +1,26 @@
+package vaultclient
+
+import (
+	"path"
+
+	"github.com/golang/glog"
+	vault "github.com/hashicorp/vault/api"
+)
+
+// CertAuthenticator is a vault authenticator that logs in using the TLS
+// client certificate the vault client has been configured with
+type CertAuthenticator struct {
+	MountPath string
+	Role      string
+}
+
+// Login logs in to vault using the cert auth method
+func (a *CertAuthenticator) Login(client *vault.Client) (*vault.SecretAuth, error) {
+	glog.V(2).Info("Logging in to vault using cert auth")
+	data := map[string]interface{}{}
+	// Without a role name vault will try all the roles that match the certificate
+	if a.Role != "" {
+		data["name"] = a.Role
+	}
+	return login(client, path.Join("auth", a.MountPath, "login"), data)
+}
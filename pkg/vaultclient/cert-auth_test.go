@@ -0,0 +1,69 @@
+package vaultclient
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/model"
+)
+
+func TestCertLogin(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/auth/cert/login" {
+			t.Errorf("Got unexpected login path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "vault-initializer" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors": ["invalid certificate or no client certificate supplied"]}`))
+			return
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Decoding login request resulted in an error: %v", err)
+		}
+		if body["name"] != "initializer" {
+			t.Errorf("Got unexpected role name: %s", body["name"])
+		}
+		w.Write([]byte(`{"auth": {"client_token": "certtoken", "lease_duration": 600, "renewable": true}}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	clientCert, clientKey := generateCertificate(t, "vault-initializer")
+	config := &model.Config{VaultAddress: server.URL, VaultAuthMode: "Cert", VaultRole: "initializer"}
+	secrets := map[string]string{
+		"vaultCACert":     certificatePEM(server.Certificate()),
+		"vaultClientCert": clientCert,
+		"vaultClientKey":  clientKey,
+	}
+
+	client, err := NewClient(config, secrets)
+	if err != nil {
+		t.Fatalf("Creating client resulted in an error: %v", err)
+	}
+	authenticator, err := CreateAuthenticator(config, secrets)
+	if err != nil {
+		t.Fatalf("Creating authenticator resulted in an error: %v", err)
+	}
+
+	auth, err := authenticator.Login(client)
+	if err != nil {
+		t.Fatalf("Logging in resulted in an error: %v", err)
+	}
+	if auth.ClientToken != "certtoken" {
+		t.Errorf("Got unexpected client token: %s", auth.ClientToken)
+	}
+}
+
+func TestCertAuthRequiresClientCertificate(t *testing.T) {
+	config := &model.Config{VaultAuthMode: "Cert"}
+	if _, err := CreateAuthenticator(config, map[string]string{}); err == nil {
+		t.Error("Creating authenticator resulted in no error where an error was expected")
+	}
+}
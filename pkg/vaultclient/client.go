@@ -0,0 +1,76 @@
+package vaultclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/richardcase/vault-initializer/pkg/model"
+)
+
+const (
+	caCertSecretKey     = "vaultCACert"
+	clientCertSecretKey = "vaultClientCert"
+	clientKeySecretKey  = "vaultClientKey"
+)
+
+// NewClient creates a vault client from the initializer configuration. The
+// client is long lived and shared by all workers, so the token is supplied
+// on each request rather than being set on the client. Any TLS certificate
+// material is read from the initializer secret.
+func NewClient(config *model.Config, secrets map[string]string) (*vault.Client, error) {
+	vaultConfig := vault.DefaultConfig()
+	if config.VaultAddress != "" {
+		vaultConfig.Address = config.VaultAddress
+	}
+	if config.VaultTimeout > 0 {
+		vaultConfig.HttpClient.Timeout = config.VaultTimeout
+	}
+	vaultConfig.MaxRetries = config.VaultMaxRetries
+
+	if err := configureTLS(vaultConfig, config, secrets); err != nil {
+		return nil, err
+	}
+
+	return vault.NewClient(vaultConfig)
+}
+
+func configureTLS(vaultConfig *vault.Config, config *model.Config, secrets map[string]string) error {
+	transport, ok := vaultConfig.HttpClient.Transport.(*http.Transport)
+	if !ok {
+		return errors.New("Unable to configure TLS on the vault http client")
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	tlsConfig := transport.TLSClientConfig
+
+	if caCert := secrets[caCertSecretKey]; caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return errors.New("Unable to parse the vault CA certificate in " + caCertSecretKey)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	clientCert, clientKey := secrets[clientCertSecretKey], secrets[clientKeySecretKey]
+	if clientCert != "" || clientKey != "" {
+		if clientCert == "" || clientKey == "" {
+			return errors.New("Both " + clientCertSecretKey + " and " + clientKeySecretKey + " must be set to use a client certificate")
+		}
+		certificate, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if config.VaultTLSServerName != "" {
+		tlsConfig.ServerName = config.VaultTLSServerName
+	}
+	tlsConfig.InsecureSkipVerify = config.VaultTLSSkipVerify
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package vaultclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/richardcase/vault-initializer/pkg/model"
+)
+
+func TestNewClientAddress(t *testing.T) {
+	config := &model.Config{VaultAddress: "http://vault.example.com:8200"}
+
+	client, err := NewClient(config, map[string]string{})
+	if err != nil {
+		t.Fatalf("Creating client resulted in an error: %v", err)
+	}
+	if client.Address() != "http://vault.example.com:8200" {
+		t.Errorf("Got unexpected address: %s", client.Address())
+	}
+}
+
+func TestNewClientInvalidAddress(t *testing.T) {
+	config := &model.Config{VaultAddress: "://vault"}
+
+	if _, err := NewClient(config, map[string]string{}); err == nil {
+		t.Error("Creating client resulted in no error where an error was expected")
+	}
+}
+
+func TestNewClientWithoutRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	config := &model.Config{VaultAddress: server.URL, VaultMaxRetries: 0}
+	client, err := NewClient(config, map[string]string{})
+	if err != nil {
+		t.Fatalf("Creating client resulted in an error: %v", err)
+	}
+
+	if err = healthCheck(client); err == nil {
+		t.Error("Request to vault resulted in no error where an error was expected")
+	}
+	if requests != 1 {
+		t.Errorf("Got unexpected number of requests: %d", requests)
+	}
+}
+
+func TestNewClientWithCACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(healthHandler))
+	defer server.Close()
+
+	config := &model.Config{VaultAddress: server.URL}
+	secrets := map[string]string{"vaultCACert": certificatePEM(server.Certificate())}
+	client, err := NewClient(config, secrets)
+	if err != nil {
+		t.Fatalf("Creating client resulted in an error: %v", err)
+	}
+
+	if err = healthCheck(client); err != nil {
+		t.Errorf("Request to vault with a trusted CA resulted in an error: %v", err)
+	}
+}
+
+func TestNewClientWithoutCACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(healthHandler))
+	defer server.Close()
+
+	config := &model.Config{VaultAddress: server.URL, VaultMaxRetries: 1}
+	client, err := NewClient(config, map[string]string{})
+	if err != nil {
+		t.Fatalf("Creating client resulted in an error: %v", err)
+	}
+
+	if err = healthCheck(client); err == nil {
+		t.Error("Request to vault with an untrusted CA resulted in no error where an error was expected")
+	}
+}
+
+func TestNewClientWithClientCertificate(t *testing.T) {
+	var peerName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			peerName = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		healthHandler(w, r)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	clientCert, clientKey := generateCertificate(t, "vault-initializer")
+	config := &model.Config{VaultAddress: server.URL}
+	secrets := map[string]string{
+		"vaultCACert":     certificatePEM(server.Certificate()),
+		"vaultClientCert": clientCert,
+		"vaultClientKey":  clientKey,
+	}
+	client, err := NewClient(config, secrets)
+	if err != nil {
+		t.Fatalf("Creating client resulted in an error: %v", err)
+	}
+
+	if err = healthCheck(client); err != nil {
+		t.Fatalf("Request to vault with a client certificate resulted in an error: %v", err)
+	}
+	if peerName != "vault-initializer" {
+		t.Errorf("Got unexpected client certificate name: %s", peerName)
+	}
+}
+
+func TestNewClientInvalidCACert(t *testing.T) {
+	secrets := map[string]string{"vaultCACert": "not a certificate"}
+
+	if _, err := NewClient(&model.Config{}, secrets); err == nil {
+		t.Error("Creating client resulted in no error where an error was expected")
+	}
+}
+
+func TestNewClientCertificateWithoutKey(t *testing.T) {
+	clientCert, _ := generateCertificate(t, "vault-initializer")
+	secrets := map[string]string{"vaultClientCert": clientCert}
+
+	if _, err := NewClient(&model.Config{}, secrets); err == nil {
+		t.Error("Creating client resulted in no error where an error was expected")
+	}
+}
+
+func TestConfigureTLSOptions(t *testing.T) {
+	vaultConfig := vault.DefaultConfig()
+	config := &model.Config{VaultTLSServerName: "vault.internal", VaultTLSSkipVerify: true}
+
+	if err := configureTLS(vaultConfig, config, map[string]string{}); err != nil {
+		t.Fatalf("Configuring TLS resulted in an error: %v", err)
+	}
+
+	tlsConfig := vaultConfig.HttpClient.Transport.(*http.Transport).TLSClientConfig
+	if tlsConfig.ServerName != "vault.internal" {
+		t.Errorf("Got unexpected server name: %s", tlsConfig.ServerName)
+	}
+	if !tlsConfig.InsecureSkipVerify {
+		t.Error("Expected TLS verification to be disabled")
+	}
+}
+
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(`{"initialized": true, "sealed": false}`))
+}
+
+func healthCheck(client *vault.Client) error {
+	resp, err := client.RawRequest(client.NewRequest("GET", "/v1/sys/health"))
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	return err
+}
+
+func certificatePEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+// generateCertificate creates a self signed certificate and returns the PEM
+// encoded certificate and key
+func generateCertificate(t *testing.T, commonName string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Generating key resulted in an error: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Creating certificate resulted in an error: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Marshalling key resulted in an error: %v", err)
+	}
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return string(cert), string(keyPEM)
+}
@@ -0,0 +1,33 @@
+package vaultclient
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/golang/glog"
+	vault "github.com/hashicorp/vault/api"
+)
+
+// KubernetesAuthenticator is a vault authenticator that logs in using the
+// service account token of the initializer
+type KubernetesAuthenticator struct {
+	MountPath string
+	Role      string
+	TokenPath string
+}
+
+// Login logs in to vault using the kubernetes auth method
+func (a *KubernetesAuthenticator) Login(client *vault.Client) (*vault.SecretAuth, error) {
+	jwt, err := ioutil.ReadFile(a.TokenPath)
+	if err != nil {
+		return nil, err
+	}
+
+	glog.V(2).Infof("Logging in to vault using kubernetes auth with role %s", a.Role)
+	data := map[string]interface{}{
+		"role": a.Role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	}
+	return login(client, path.Join("auth", a.MountPath, "login"), data)
+}
@@ -0,0 +1,136 @@
+package vaultclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/richardcase/vault-initializer/pkg/model"
+)
+
+func TestKubernetesLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/auth/kubernetes/login" {
+			t.Errorf("Got unexpected login path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Decoding login request resulted in an error: %v", err)
+		}
+		if body["role"] != "initializer" {
+			t.Errorf("Got unexpected role: %s", body["role"])
+		}
+		if body["jwt"] != "a.service.account.jwt" {
+			t.Errorf("Got unexpected jwt: %s", body["jwt"])
+		}
+		w.Write([]byte(`{"auth": {"client_token": "kubetoken", "lease_duration": 3600, "renewable": true}}`))
+	}))
+	defer server.Close()
+
+	tokenPath := writeTokenFile(t, "a.service.account.jwt\n")
+	defer os.Remove(tokenPath)
+
+	config := &model.Config{VaultAuthMode: "Kubernetes", VaultRole: "initializer", ServiceAccountTokenPath: tokenPath}
+	authenticator, err := CreateAuthenticator(config, map[string]string{})
+	if err != nil {
+		t.Fatalf("Creating authenticator resulted in an error: %v", err)
+	}
+
+	auth, err := authenticator.Login(testClient(t, server.URL))
+	if err != nil {
+		t.Fatalf("Logging in resulted in an error: %v", err)
+	}
+	if auth.ClientToken != "kubetoken" {
+		t.Errorf("Got unexpected client token: %s", auth.ClientToken)
+	}
+	if auth.LeaseDuration != 3600 {
+		t.Errorf("Got unexpected lease duration: %d", auth.LeaseDuration)
+	}
+}
+
+func TestKubernetesLoginCustomMountPath(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = r.URL.Path == "/v1/auth/k8s-cluster1/login"
+		w.Write([]byte(`{"auth": {"client_token": "kubetoken"}}`))
+	}))
+	defer server.Close()
+
+	tokenPath := writeTokenFile(t, "a.service.account.jwt")
+	defer os.Remove(tokenPath)
+
+	config := &model.Config{VaultAuthMode: "Kubernetes", VaultAuthPath: "k8s-cluster1", VaultRole: "initializer", ServiceAccountTokenPath: tokenPath}
+	authenticator, err := CreateAuthenticator(config, map[string]string{})
+	if err != nil {
+		t.Fatalf("Creating authenticator resulted in an error: %v", err)
+	}
+
+	if _, err = authenticator.Login(testClient(t, server.URL)); err != nil {
+		t.Fatalf("Logging in resulted in an error: %v", err)
+	}
+	if !called {
+		t.Error("Expected login to use the custom auth mount path")
+	}
+}
+
+func TestKubernetesLoginDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errors": ["invalid role name \"initializer\""]}`))
+	}))
+	defer server.Close()
+
+	tokenPath := writeTokenFile(t, "a.service.account.jwt")
+	defer os.Remove(tokenPath)
+
+	config := &model.Config{VaultAuthMode: "Kubernetes", VaultRole: "initializer", ServiceAccountTokenPath: tokenPath}
+	authenticator, err := CreateAuthenticator(config, map[string]string{})
+	if err != nil {
+		t.Fatalf("Creating authenticator resulted in an error: %v", err)
+	}
+
+	if _, err = authenticator.Login(testClient(t, server.URL)); err == nil {
+		t.Error("Logging in resulted in no error where an error was expected")
+	}
+}
+
+func TestKubernetesAuthRequiresRole(t *testing.T) {
+	config := &model.Config{VaultAuthMode: "Kubernetes"}
+	if _, err := CreateAuthenticator(config, map[string]string{}); err == nil {
+		t.Error("Creating authenticator resulted in no error where an error was expected")
+	}
+}
+
+func TestInvalidAuthMode(t *testing.T) {
+	config := &model.Config{VaultAuthMode: "Userpass"}
+	if _, err := CreateAuthenticator(config, map[string]string{}); err == nil {
+		t.Error("Creating authenticator resulted in no error where an error was expected")
+	}
+}
+
+func testClient(t *testing.T, address string) *vault.Client {
+	client, err := vault.NewClient(&vault.Config{Address: address, HttpClient: http.DefaultClient})
+	if err != nil {
+		t.Fatalf("Creating vault client resulted in an error: %v", err)
+	}
+	client.ClearToken()
+	return client
+}
+
+func writeTokenFile(t *testing.T, token string) string {
+	f, err := ioutil.TempFile("", "token")
+	if err != nil {
+		t.Fatalf("Creating token file resulted in an error: %v", err)
+	}
+	defer f.Close()
+	if _, err = f.WriteString(token); err != nil {
+		t.Fatalf("Writing token file resulted in an error: %v", err)
+	}
+	return f.Name()
+}
@@ -0,0 +1,140 @@
+package vaultclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	vault "github.com/hashicorp/vault/api"
+)
+
+const apiPrefix = "/v1/"
+
+// KVSecret is a secret read from a kv secrets engine
+type KVSecret struct {
+	Data map[string]interface{}
+	// Version is the version of the secret read from a KV v2 engine, it is
+	// zero for KV v1
+	Version int
+}
+
+// ReadKV reads the secret at a path from a kv secrets engine. The engine
+// version can be "1" or "2", or empty to detect the version from the mount.
+// For KV v2 a specific version of the secret can be requested, zero reads
+// the latest version. A nil secret is returned if there is no secret at the path.
+func ReadKV(client *vault.Client, token, secretPath, engineVersion string, version int) (*KVSecret, error) {
+	logicalPath := strings.TrimPrefix(strings.TrimPrefix(secretPath, apiPrefix), "/")
+
+	var mountPath string
+	switch engineVersion {
+	case "1":
+	case "2":
+		mountPath = strings.SplitN(logicalPath, "/", 2)[0] + "/"
+	case "":
+		var err error
+		engineVersion, mountPath, err = detectKVVersion(client, token, logicalPath)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("Invalid KV Version: %s", engineVersion)
+	}
+
+	if engineVersion != "2" {
+		if version > 0 {
+			return nil, fmt.Errorf("Unable to read version %d of %s as secret versions require KV version 2", version, logicalPath)
+		}
+		secret, err := read(client, token, logicalPath, nil)
+		if err != nil || secret == nil {
+			return nil, err
+		}
+		return &KVSecret{Data: secret.Data}, nil
+	}
+
+	logicalPath = mountPath + "data/" + strings.TrimPrefix(logicalPath, mountPath)
+	params := url.Values{}
+	if version > 0 {
+		params.Set("version", strconv.Itoa(version))
+	}
+
+	secret, err := read(client, token, logicalPath, params)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+
+	// KV v2 wraps the secret in a data key alongside the version metadata.
+	// The data is null if the version has been deleted.
+	if secret.Data["data"] == nil {
+		return nil, nil
+	}
+	data, ok := secret.Data["data"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("Unexpected data returned from KV v2 path " + logicalPath)
+	}
+
+	kvSecret := &KVSecret{Data: data}
+	if metadata, ok := secret.Data["metadata"].(map[string]interface{}); ok {
+		if v, ok := metadata["version"].(json.Number); ok {
+			resolved, err := v.Int64()
+			if err != nil {
+				return nil, err
+			}
+			kvSecret.Version = int(resolved)
+		}
+	}
+	return kvSecret, nil
+}
+
+// detectKVVersion returns the kv engine version and mount path for a path
+func detectKVVersion(client *vault.Client, token, logicalPath string) (string, string, error) {
+	secret, err := read(client, token, "sys/internal/ui/mounts/"+logicalPath, nil)
+	if err != nil {
+		return "", "", err
+	}
+	// Versions of vault before KV v2 don't have the endpoint
+	if secret == nil || secret.Data == nil {
+		glog.V(2).Infof("Unable to detect KV version for %s, assuming version 1", logicalPath)
+		return "1", "", nil
+	}
+
+	mountPath, _ := secret.Data["path"].(string)
+	version := "1"
+	if options, ok := secret.Data["options"].(map[string]interface{}); ok {
+		if v, ok := options["version"].(string); ok && v != "" {
+			version = v
+		}
+	}
+	glog.V(2).Infof("Detected KV version %s mounted at %s for %s", version, mountPath, logicalPath)
+	return version, mountPath, nil
+}
+
+// read reads a path from vault, returning nil if there is nothing at the path
+func read(client *vault.Client, token, logicalPath string, params url.Values) (*vault.Secret, error) {
+	request := client.NewRequest("GET", apiPrefix+logicalPath)
+	request.ClientToken = token
+	for key, values := range params {
+		for _, value := range values {
+			request.Params.Add(key, value)
+		}
+	}
+
+	resp, err := client.RawRequest(request)
+	if resp != nil && resp.Body != nil {
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return vault.ParseSecret(resp.Body)
+}
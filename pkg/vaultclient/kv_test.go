@@ -0,0 +1,220 @@
+package vaultclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeKVVault is a fake vault server that returns canned responses for paths
+type fakeKVVault map[string]string
+
+func (f fakeKVVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != "atoken" {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors": ["permission denied"]}`))
+		return
+	}
+	path := r.URL.Path
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
+	}
+	body, ok := f[path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors": []}`))
+		return
+	}
+	w.Write([]byte(body))
+}
+
+const (
+	kv1Mount   = `{"data": {"path": "secret/", "type": "kv", "options": null}}`
+	kv2Mount   = `{"data": {"path": "secret/", "type": "kv", "options": {"version": "2"}}}`
+	kv1Secret  = `{"data": {"username": "admin", "password": "Password123"}}`
+	kv2Secret  = `{"data": {"data": {"username": "admin", "password": "Password123"}, "metadata": {"version": 3}}}`
+	kv2Deleted = `{"data": {"data": null, "metadata": {"version": 3, "deletion_time": "2018-03-01T10:00:00Z"}}}`
+)
+
+func TestReadKVVersion1(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{
+		"/v1/secret/default/app": kv1Secret,
+	})
+	defer server.Close()
+
+	secret, err := ReadKV(testClient(t, server.URL), "atoken", "/v1/secret/default/app", "1", 0)
+	if err != nil {
+		t.Fatalf("Reading secret resulted in an error: %v", err)
+	}
+	assertCredentials(t, secret.Data)
+}
+
+func TestReadKVVersion2(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{
+		"/v1/secret/data/default/app": kv2Secret,
+	})
+	defer server.Close()
+
+	secret, err := ReadKV(testClient(t, server.URL), "atoken", "/v1/secret/default/app", "2", 0)
+	if err != nil {
+		t.Fatalf("Reading secret resulted in an error: %v", err)
+	}
+	assertCredentials(t, secret.Data)
+}
+
+func TestReadKVVersion2ResolvedVersion(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{
+		"/v1/secret/data/default/app": kv2Secret,
+	})
+	defer server.Close()
+
+	secret, err := ReadKV(testClient(t, server.URL), "atoken", "/v1/secret/default/app", "2", 0)
+	if err != nil {
+		t.Fatalf("Reading secret resulted in an error: %v", err)
+	}
+	if secret.Version != 3 {
+		t.Errorf("Got unexpected version: %d", secret.Version)
+	}
+}
+
+func TestReadKVPinnedVersion(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{
+		"/v1/secret/data/default/app":           kv2Secret,
+		"/v1/secret/data/default/app?version=2": `{"data": {"data": {"username": "admin", "password": "OldPassword"}, "metadata": {"version": 2}}}`,
+	})
+	defer server.Close()
+
+	secret, err := ReadKV(testClient(t, server.URL), "atoken", "/v1/secret/default/app", "2", 2)
+	if err != nil {
+		t.Fatalf("Reading secret resulted in an error: %v", err)
+	}
+	if secret.Version != 2 {
+		t.Errorf("Got unexpected version: %d", secret.Version)
+	}
+	if secret.Data["password"] != "OldPassword" {
+		t.Errorf("Got unexpected password: %v", secret.Data["password"])
+	}
+}
+
+func TestReadKVVersion1PinnedVersion(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{
+		"/v1/secret/default/app": kv1Secret,
+	})
+	defer server.Close()
+
+	if _, err := ReadKV(testClient(t, server.URL), "atoken", "/v1/secret/default/app", "1", 2); err == nil {
+		t.Error("Reading secret resulted in no error where an error was expected")
+	}
+}
+
+func TestReadKVDetectVersion1(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{
+		"/v1/sys/internal/ui/mounts/secret/default/app": kv1Mount,
+		"/v1/secret/default/app":                        kv1Secret,
+	})
+	defer server.Close()
+
+	secret, err := ReadKV(testClient(t, server.URL), "atoken", "/v1/secret/default/app", "", 0)
+	if err != nil {
+		t.Fatalf("Reading secret resulted in an error: %v", err)
+	}
+	assertCredentials(t, secret.Data)
+}
+
+func TestReadKVDetectVersion2(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{
+		"/v1/sys/internal/ui/mounts/secret/default/app": kv2Mount,
+		"/v1/secret/data/default/app":                   kv2Secret,
+	})
+	defer server.Close()
+
+	secret, err := ReadKV(testClient(t, server.URL), "atoken", "/v1/secret/default/app", "", 0)
+	if err != nil {
+		t.Fatalf("Reading secret resulted in an error: %v", err)
+	}
+	assertCredentials(t, secret.Data)
+}
+
+func TestReadKVDetectNestedMount(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{
+		"/v1/sys/internal/ui/mounts/teams/payments/default/app": `{"data": {"path": "teams/payments/", "type": "kv", "options": {"version": "2"}}}`,
+		"/v1/teams/payments/data/default/app":                   kv2Secret,
+	})
+	defer server.Close()
+
+	secret, err := ReadKV(testClient(t, server.URL), "atoken", "/v1/teams/payments/default/app", "", 0)
+	if err != nil {
+		t.Fatalf("Reading secret resulted in an error: %v", err)
+	}
+	assertCredentials(t, secret.Data)
+}
+
+func TestReadKVDetectWithoutMountsEndpoint(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{
+		"/v1/secret/default/app": kv1Secret,
+	})
+	defer server.Close()
+
+	secret, err := ReadKV(testClient(t, server.URL), "atoken", "/v1/secret/default/app", "", 0)
+	if err != nil {
+		t.Fatalf("Reading secret resulted in an error: %v", err)
+	}
+	assertCredentials(t, secret.Data)
+}
+
+func TestReadKVNotFound(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{})
+	defer server.Close()
+
+	secret, err := ReadKV(testClient(t, server.URL), "atoken", "/v1/secret/default/app", "2", 0)
+	if err != nil {
+		t.Fatalf("Reading secret resulted in an error: %v", err)
+	}
+	if secret != nil {
+		t.Errorf("Expected no secret but got %v", secret)
+	}
+}
+
+func TestReadKVDeleted(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{
+		"/v1/secret/data/default/app": kv2Deleted,
+	})
+	defer server.Close()
+
+	secret, err := ReadKV(testClient(t, server.URL), "atoken", "/v1/secret/default/app", "2", 0)
+	if err != nil {
+		t.Fatalf("Reading secret resulted in an error: %v", err)
+	}
+	if secret != nil {
+		t.Errorf("Expected no secret but got %v", secret)
+	}
+}
+
+func TestReadKVPermissionDenied(t *testing.T) {
+	server := httptest.NewServer(fakeKVVault{
+		"/v1/secret/default/app": kv1Secret,
+	})
+	defer server.Close()
+
+	if _, err := ReadKV(testClient(t, server.URL), "wrongtoken", "/v1/secret/default/app", "1", 0); err == nil {
+		t.Error("Reading secret resulted in no error where an error was expected")
+	}
+}
+
+func TestReadKVInvalidVersion(t *testing.T) {
+	if _, err := ReadKV(nil, "atoken", "/v1/secret/default/app", "3", 0); err == nil {
+		t.Error("Reading secret resulted in no error where an error was expected")
+	}
+}
+
+func assertCredentials(t *testing.T, data map[string]interface{}) {
+	if data["username"] != "admin" {
+		t.Errorf("Got unexpected username: %v", data["username"])
+	}
+	if data["password"] != "Password123" {
+		t.Errorf("Got unexpected password: %v", data["password"])
+	}
+	if len(data) != 2 {
+		t.Errorf("Expected 2 secrets but got %d", len(data))
+	}
+}
@@ -0,0 +1,147 @@
+package vaultclient
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	vault "github.com/hashicorp/vault/api"
+)
+
+const (
+	// loginRetryInterval is how long to wait before trying to log in again after a failure
+	loginRetryInterval = 10 * time.Second
+	// minimumTokenTTL is the smallest remaining TTL that is worth renewing, below it we log in again
+	minimumTokenTTL = 5 * time.Second
+)
+
+// TokenManager keeps a vault token valid. It tracks the TTL from the login
+// response, renews the token in the background before it expires and logs
+// in again when the token can no longer be renewed.
+type TokenManager struct {
+	client        *vault.Client
+	authenticator Authenticator
+
+	lock sync.RWMutex
+	auth *vault.SecretAuth
+}
+
+// NewTokenManager creates a new token manager
+func NewTokenManager(client *vault.Client, authenticator Authenticator) *TokenManager {
+	return &TokenManager{
+		client:        client,
+		authenticator: authenticator,
+	}
+}
+
+// Start logs in to vault and then keeps the token valid until stopCh is closed
+func (m *TokenManager) Start(stopCh <-chan struct{}) error {
+	auth, err := m.authenticator.Login(m.client)
+	if err != nil {
+		return err
+	}
+	m.setAuth(auth)
+
+	go m.run(renewAfter(auth), stopCh)
+	return nil
+}
+
+// Token returns the current vault token. It is safe to call from multiple goroutines.
+func (m *TokenManager) Token() (string, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if m.auth == nil || m.auth.ClientToken == "" {
+		return "", errors.New("No valid vault token available")
+	}
+	return m.auth.ClientToken, nil
+}
+
+func (m *TokenManager) run(wait time.Duration, stopCh <-chan struct{}) {
+	for {
+		// A zero wait means the token doesn't expire so there is nothing to do
+		var timer <-chan time.Time
+		if wait > 0 {
+			timer = time.After(wait)
+		}
+
+		select {
+		case <-stopCh:
+			glog.Info("Stopping vault token manager")
+			return
+		case <-timer:
+			wait = m.refresh()
+		}
+	}
+}
+
+// refresh renews the current token, or logs in again if it can't be renewed,
+// and returns how long to wait before the next refresh
+func (m *TokenManager) refresh() time.Duration {
+	m.lock.RLock()
+	current := m.auth
+	m.lock.RUnlock()
+
+	if current != nil && current.Renewable {
+		auth, err := m.renew(current.ClientToken)
+		if err == nil && time.Duration(auth.LeaseDuration)*time.Second >= minimumTokenTTL {
+			glog.V(2).Infof("Renewed vault token, new TTL is %ds", auth.LeaseDuration)
+			m.setAuth(auth)
+			return renewAfter(auth)
+		}
+		if err != nil {
+			glog.Warningf("Error renewing vault token, logging in again: %v", err)
+		} else {
+			glog.V(2).Info("Vault token has reached its max TTL, logging in again")
+		}
+	}
+
+	auth, err := m.authenticator.Login(m.client)
+	if err != nil {
+		glog.Errorf("Error logging in to vault, retrying in %s: %v", loginRetryInterval, err)
+		return loginRetryInterval
+	}
+	glog.V(2).Infof("Logged in to vault, token TTL is %ds", auth.LeaseDuration)
+	m.setAuth(auth)
+	return renewAfter(auth)
+}
+
+func (m *TokenManager) renew(token string) (*vault.SecretAuth, error) {
+	request := m.client.NewRequest("PUT", "/v1/auth/token/renew-self")
+	request.ClientToken = token
+
+	resp, err := m.client.RawRequest(request)
+	if resp != nil && resp.Body != nil {
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := vault.ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Auth == nil {
+		return nil, errors.New("No auth information returned when renewing token")
+	}
+	return secret.Auth, nil
+}
+
+func (m *TokenManager) setAuth(auth *vault.SecretAuth) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.auth = auth
+}
+
+// renewAfter returns how long to wait before refreshing a token, which is
+// two thirds of its TTL. Zero is returned for tokens that don't expire.
+func renewAfter(auth *vault.SecretAuth) time.Duration {
+	if auth.LeaseDuration <= 0 {
+		return 0
+	}
+	return time.Duration(auth.LeaseDuration) * time.Second * 2 / 3
+}
@@ -0,0 +1,174 @@
+package vaultclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// fakeTokenVault is a fake vault server that issues and renews tokens
+type fakeTokenVault struct {
+	lock       sync.Mutex
+	logins     int
+	renewals   int
+	renewable  bool
+	failRenew  bool
+	renewedTTL int
+}
+
+func (f *fakeTokenVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	switch r.URL.Path {
+	case "/v1/auth/approle/login":
+		f.logins++
+		fmt.Fprintf(w, `{"auth": {"client_token": "token-%d", "lease_duration": 60, "renewable": %t}}`, f.logins, f.renewable)
+	case "/v1/auth/token/renew-self":
+		f.renewals++
+		if f.failRenew {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors": ["permission denied"]}`))
+			return
+		}
+		fmt.Fprintf(w, `{"auth": {"client_token": "%s", "lease_duration": %d, "renewable": true}}`, r.Header.Get("X-Vault-Token"), f.renewedTTL)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// counts returns the number of logins and renewals the server has handled
+func (f *fakeTokenVault) counts() (int, int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.logins, f.renewals
+}
+
+func newTestTokenManager(t *testing.T, fake *fakeTokenVault) (*TokenManager, func()) {
+	server := httptest.NewServer(fake)
+	authenticator := &AppRoleAuthenticator{MountPath: "approle", RoleID: "arole", SecretID: "asecret"}
+	return NewTokenManager(testClient(t, server.URL), authenticator), server.Close
+}
+
+func TestTokenManagerStart(t *testing.T) {
+	fake := &fakeTokenVault{renewable: true}
+	manager, cleanup := newTestTokenManager(t, fake)
+	defer cleanup()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	if err := manager.Start(stopCh); err != nil {
+		t.Fatalf("Starting token manager resulted in an error: %v", err)
+	}
+	token, err := manager.Token()
+	if err != nil {
+		t.Fatalf("Getting token resulted in an error: %v", err)
+	}
+	if token != "token-1" {
+		t.Errorf("Got unexpected token: %s", token)
+	}
+}
+
+func TestTokenManagerNoToken(t *testing.T) {
+	manager, cleanup := newTestTokenManager(t, &fakeTokenVault{})
+	defer cleanup()
+
+	if _, err := manager.Token(); err == nil {
+		t.Error("Getting token resulted in no error where an error was expected")
+	}
+}
+
+func TestTokenManagerRenews(t *testing.T) {
+	fake := &fakeTokenVault{renewable: true, renewedTTL: 30}
+	manager, cleanup := newTestTokenManager(t, fake)
+	defer cleanup()
+
+	manager.setAuth(&vault.SecretAuth{ClientToken: "token-1", LeaseDuration: 60, Renewable: true})
+	wait := manager.refresh()
+
+	logins, renewals := fake.counts()
+	if renewals != 1 {
+		t.Errorf("Expected 1 renewal but got %d", renewals)
+	}
+	if logins != 0 {
+		t.Errorf("Expected no logins but got %d", logins)
+	}
+	if wait != 20*time.Second {
+		t.Errorf("Got unexpected wait before next refresh: %s", wait)
+	}
+	token, _ := manager.Token()
+	if token != "token-1" {
+		t.Errorf("Got unexpected token: %s", token)
+	}
+}
+
+func TestTokenManagerLoginWhenRenewFails(t *testing.T) {
+	fake := &fakeTokenVault{renewable: true, failRenew: true}
+	manager, cleanup := newTestTokenManager(t, fake)
+	defer cleanup()
+
+	manager.setAuth(&vault.SecretAuth{ClientToken: "token-0", LeaseDuration: 60, Renewable: true})
+	manager.refresh()
+
+	logins, renewals := fake.counts()
+	if renewals != 1 {
+		t.Errorf("Expected 1 renewal but got %d", renewals)
+	}
+	if logins != 1 {
+		t.Errorf("Expected 1 login but got %d", logins)
+	}
+	token, _ := manager.Token()
+	if token != "token-1" {
+		t.Errorf("Got unexpected token: %s", token)
+	}
+}
+
+func TestTokenManagerLoginWhenMaxTTLReached(t *testing.T) {
+	fake := &fakeTokenVault{renewable: true, renewedTTL: 1}
+	manager, cleanup := newTestTokenManager(t, fake)
+	defer cleanup()
+
+	manager.setAuth(&vault.SecretAuth{ClientToken: "token-0", LeaseDuration: 60, Renewable: true})
+	manager.refresh()
+
+	logins, _ := fake.counts()
+	if logins != 1 {
+		t.Errorf("Expected 1 login but got %d", logins)
+	}
+	token, _ := manager.Token()
+	if token != "token-1" {
+		t.Errorf("Got unexpected token: %s", token)
+	}
+}
+
+func TestTokenManagerLoginWhenNotRenewable(t *testing.T) {
+	fake := &fakeTokenVault{}
+	manager, cleanup := newTestTokenManager(t, fake)
+	defer cleanup()
+
+	manager.setAuth(&vault.SecretAuth{ClientToken: "token-0", LeaseDuration: 60})
+	manager.refresh()
+
+	logins, renewals := fake.counts()
+	if renewals != 0 {
+		t.Errorf("Expected no renewals but got %d", renewals)
+	}
+	if logins != 1 {
+		t.Errorf("Expected 1 login but got %d", logins)
+	}
+}
+
+func TestRenewAfter(t *testing.T) {
+	if wait := renewAfter(&vault.SecretAuth{LeaseDuration: 0}); wait != 0 {
+		t.Errorf("Expected no refresh for a non-expiring token but got %s", wait)
+	}
+	if wait := renewAfter(&vault.SecretAuth{LeaseDuration: 3600}); wait != 40*time.Minute {
+		t.Errorf("Got unexpected wait before next refresh: %s", wait)
+	}
+}
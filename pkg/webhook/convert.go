@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// conversionReview is the ConversionReview sent to custom resource
+// conversion webhooks by the API server
+type conversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *conversionRequest  `json:"request,omitempty"`
+	Response        *conversionResponse `json:"response,omitempty"`
+}
+
+type conversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+type conversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+// serveConvert converts vault maps between the versions of the API
+func (s *Server) serveConvert(w http.ResponseWriter, r *http.Request) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+		http.Error(w, fmt.Sprintf("Invalid Content-Type %s, expected application/json", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := conversionReview{}
+	if err = json.Unmarshal(body, &review); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "ConversionReview contains no request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = convert(review.Request)
+	review.Request = nil
+
+	resp, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(resp); err != nil {
+		glog.Errorf("Error writing conversion response: %v", err)
+	}
+}
+
+// convert converts all of the objects in a request. If any of them can't be
+// converted the request fails.
+func convert(req *conversionRequest) *conversionResponse {
+	response := &conversionResponse{UID: req.UID}
+	for _, obj := range req.Objects {
+		converted, err := convertObject(obj.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			glog.Errorf("Error converting object to %s: %v", req.DesiredAPIVersion, err)
+			response.ConvertedObjects = nil
+			response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			return response
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	response.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return response
+}
+
+// convertObject converts an encoded vault map or cluster vault map to an
+// API version
+func convertObject(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, err
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	alpha := v1alpha1.SchemeGroupVersion.String()
+	beta := v1beta1.SchemeGroupVersion.String()
+	var converted interface{}
+	switch {
+	case typeMeta.Kind == "VaultMap" && typeMeta.APIVersion == alpha && desiredAPIVersion == beta:
+		vaultmap := &v1alpha1.VaultMap{}
+		if err := json.Unmarshal(raw, vaultmap); err != nil {
+			return nil, err
+		}
+		converted = v1beta1.VaultMapFromV1alpha1(vaultmap)
+	case typeMeta.Kind == "VaultMap" && typeMeta.APIVersion == beta && desiredAPIVersion == alpha:
+		vaultmap := &v1beta1.VaultMap{}
+		if err := json.Unmarshal(raw, vaultmap); err != nil {
+			return nil, err
+		}
+		converted = v1beta1.VaultMapToV1alpha1(vaultmap)
+	case typeMeta.Kind == "ClusterVaultMap" && typeMeta.APIVersion == alpha && desiredAPIVersion == beta:
+		clusterMap := &v1alpha1.ClusterVaultMap{}
+		if err := json.Unmarshal(raw, clusterMap); err != nil {
+			return nil, err
+		}
+		converted = v1beta1.ClusterVaultMapFromV1alpha1(clusterMap)
+	case typeMeta.Kind == "ClusterVaultMap" && typeMeta.APIVersion == beta && desiredAPIVersion == alpha:
+		clusterMap := &v1beta1.ClusterVaultMap{}
+		if err := json.Unmarshal(raw, clusterMap); err != nil {
+			return nil, err
+		}
+		converted = v1beta1.ClusterVaultMapToV1alpha1(clusterMap)
+	default:
+		return nil, fmt.Errorf("Unsupported conversion of %s %s to %s", typeMeta.APIVersion, typeMeta.Kind, desiredAPIVersion)
+	}
+	return json.Marshal(converted)
+}
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestConvertVaultMap(t *testing.T) {
+	server, cleanup := newTestServer(t)
+	defer cleanup()
+
+	vaultmap := testVaultMap()
+	vaultmap.TypeMeta = metav1.TypeMeta{APIVersion: "vaultinit.k8s.io/v1alpha1", Kind: "VaultMap"}
+
+	response := convertReview(t, server, "vaultinit.k8s.io/v1beta1", vaultmap)
+	if response.Result.Status != metav1.StatusSuccess || len(response.ConvertedObjects) != 1 {
+		t.Fatalf("Got unexpected conversion response: %v", response)
+	}
+
+	converted := &v1beta1.VaultMap{}
+	if err := json.Unmarshal(response.ConvertedObjects[0].Raw, converted); err != nil {
+		t.Fatalf("Decoding converted vault map resulted in an error: %v", err)
+	}
+	if converted.APIVersion != "vaultinit.k8s.io/v1beta1" || converted.Name != vaultmap.Name {
+		t.Errorf("Got unexpected converted vault map: %v", converted)
+	}
+	if len(converted.Spec.Paths) != 1 || converted.Spec.Paths[0].Path != vaultmap.Spec.VaultPathPattern {
+		t.Errorf("Got unexpected paths: %v", converted.Spec.Paths)
+	}
+
+	response = convertReview(t, server, "vaultinit.k8s.io/v1alpha1", converted)
+	original := &v1alpha1.VaultMap{}
+	if err := json.Unmarshal(response.ConvertedObjects[0].Raw, original); err != nil {
+		t.Fatalf("Decoding converted vault map resulted in an error: %v", err)
+	}
+	if original.Spec.VaultPathPattern != vaultmap.Spec.VaultPathPattern || original.Spec.SecretsPublisher != vaultmap.Spec.SecretsPublisher {
+		t.Errorf("Got unexpected vault map after round trip: %v", original)
+	}
+}
+
+func TestConvertUnsupportedKind(t *testing.T) {
+	server, cleanup := newTestServer(t)
+	defer cleanup()
+
+	pod := testPod()
+	pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+
+	response := convertReview(t, server, "vaultinit.k8s.io/v1beta1", pod)
+	if response.Result.Status != metav1.StatusFailure || len(response.ConvertedObjects) != 0 {
+		t.Errorf("Got unexpected conversion response: %v", response)
+	}
+}
+
+// convertReview sends an object to the conversion webhook and returns the response
+func convertReview(t *testing.T, server *Server, desiredAPIVersion string, obj runtime.Object) *conversionResponse {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("Encoding object resulted in an error: %v", err)
+	}
+	review := conversionReview{
+		Request: &conversionRequest{
+			UID:               "a-uid",
+			DesiredAPIVersion: desiredAPIVersion,
+			Objects:           []runtime.RawExtension{{Raw: raw}},
+		},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatalf("Encoding conversion review resulted in an error: %v", err)
+	}
+
+	request := httptest.NewRequest("POST", "/convert", bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	server.serveConvert(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Got unexpected status code: %d", recorder.Code)
+	}
+	response := conversionReview{}
+	if err = json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Decoding conversion review resulted in an error: %v", err)
+	}
+	if response.Response == nil || response.Response.UID != "a-uid" {
+		t.Fatalf("Got unexpected conversion response: %v", response.Response)
+	}
+	return response.Response
+}
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"encoding/json"
+
+	"github.com/golang/glog"
+	"github.com/richardcase/vault-initializer/pkg/inject"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/api/apps/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// patchOperation is an operation of a JSON patch, see RFC 6902
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+func (s *Server) mutate(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	switch req.Kind.Kind {
+	case "Deployment":
+		return s.mutateDeployment(req)
+	case "Pod":
+		return s.mutatePod(req)
+	default:
+		glog.V(2).Infof("Ignoring admission request for unsupported kind %s", req.Kind.Kind)
+		return allowedResponse()
+	}
+}
+
+func (s *Server) mutateDeployment(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	deployment := &v1beta1.Deployment{}
+	if err := json.Unmarshal(req.Object.Raw, deployment); err != nil {
+		return errorResponse(err)
+	}
+	// The namespace isn't always set on the object when it's created
+	if deployment.Namespace == "" {
+		deployment.Namespace = req.Namespace
+	}
+	glog.Infof("Admitting deployment: %s/%s", deployment.Namespace, deployment.Name)
+
+	injected, err := s.injector.Inject(inject.DeploymentWorkload(deployment))
+	if err != nil {
+		if s.injector.FailurePolicy(err) == model.FailurePolicyIgnore {
+			glog.Warningf("Error injecting secrets into deployment %s, admitting it without secrets: %v", deployment.Name, err)
+			return allowedResponse()
+		}
+		glog.Errorf("Error injecting secrets into deployment %s: %v", deployment.Name, err)
+		return errorResponse(err)
+	}
+	if !injected {
+		return allowedResponse()
+	}
+
+	patch := []patchOperation{
+		{Op: "replace", Path: "/spec/template", Value: deployment.Spec.Template},
+	}
+	return patchResponse(patch)
+}
+
+// mutatePod injects secrets into pods directly, which covers pods created by
+// any controller
+func (s *Server) mutatePod(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	pod := &corev1.Pod{}
+	if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+		return errorResponse(err)
+	}
+	if pod.Namespace == "" {
+		pod.Namespace = req.Namespace
+	}
+	workload := inject.PodWorkload(pod)
+	glog.Infof("Admitting pod: %s/%s", workload.Namespace, workload.Name)
+
+	injected, err := s.injector.Inject(workload)
+	if err != nil {
+		if s.injector.FailurePolicy(err) == model.FailurePolicyIgnore {
+			glog.Warningf("Error injecting secrets into pod %s, admitting it without secrets: %v", workload.Name, err)
+			return allowedResponse()
+		}
+		glog.Errorf("Error injecting secrets into pod %s: %v", workload.Name, err)
+		return errorResponse(err)
+	}
+	if !injected {
+		return allowedResponse()
+	}
+
+	// Adding a member that already exists replaces it
+	patch := []patchOperation{
+		{Op: "replace", Path: "/spec", Value: workload.Template.Spec},
+		{Op: "add", Path: "/metadata/annotations", Value: workload.Template.Annotations},
+	}
+	return patchResponse(patch)
+}
+
+func patchResponse(patch []patchOperation) *admissionv1beta1.AdmissionResponse {
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	patchType := admissionv1beta1.PatchTypeJSONPatch
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patchBytes,
+		PatchType: &patchType,
+	}
+}
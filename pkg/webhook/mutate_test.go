@@ -0,0 +1,353 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	mapfake "github.com/richardcase/vault-initializer/pkg/client/clientset/versioned/fake"
+	listers "github.com/richardcase/vault-initializer/pkg/client/listers/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/inject"
+	"github.com/richardcase/vault-initializer/pkg/model"
+	"github.com/richardcase/vault-initializer/pkg/vaultclient"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/api/apps/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestMutateDeployment(t *testing.T) {
+	server, cleanup := newTestServer(t, testVaultMap())
+	defer cleanup()
+
+	response := admit(t, server, "Deployment", testDeployment())
+
+	if !response.Allowed {
+		t.Fatalf("Expected deployment to be allowed: %v", response.Result)
+	}
+	if response.PatchType == nil || *response.PatchType != admissionv1beta1.PatchTypeJSONPatch {
+		t.Fatalf("Expected a JSON patch")
+	}
+
+	var patch []struct {
+		Op    string                 `json:"op"`
+		Path  string                 `json:"path"`
+		Value corev1.PodTemplateSpec `json:"value"`
+	}
+	if err := json.Unmarshal(response.Patch, &patch); err != nil {
+		t.Fatalf("Decoding patch resulted in an error: %v", err)
+	}
+	if len(patch) != 1 || patch[0].Op != "replace" || patch[0].Path != "/spec/template" {
+		t.Fatalf("Got unexpected patch: %s", string(response.Patch))
+	}
+
+	template := patch[0].Value
+	env := template.Spec.Containers[0].Env
+	if len(env) != 1 || env[0].Name != "mysecret" || env[0].Value != "Password123" {
+		t.Errorf("Got unexpected environment variables: %v", env)
+	}
+	if template.Annotations[inject.InitializedAnnotation] != "true" {
+		t.Errorf("Expected pod template to be flagged as initialized")
+	}
+}
+
+func TestMutateDeploymentContainers(t *testing.T) {
+	server, cleanup := newTestServer(t, testVaultMap())
+	defer cleanup()
+
+	deployment := testDeployment()
+	deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers,
+		corev1.Container{Name: "sidecar", Image: "busybox"},
+	)
+	response := admit(t, server, "Deployment", deployment)
+
+	var patch []struct {
+		Value corev1.PodTemplateSpec `json:"value"`
+	}
+	if err := json.Unmarshal(response.Patch, &patch); err != nil {
+		t.Fatalf("Decoding patch resulted in an error: %v", err)
+	}
+	containers := patch[0].Value.Spec.Containers
+	if len(containers[0].Env) != 1 {
+		t.Errorf("Got unexpected environment variables for envprinter: %v", containers[0].Env)
+	}
+	if len(containers[1].Env) != 0 {
+		t.Errorf("Got unexpected environment variables for sidecar: %v", containers[1].Env)
+	}
+
+	// Only the containers in the annotation get secrets
+	deployment.Annotations = map[string]string{inject.ContainersAnnotation: "sidecar"}
+	response = admit(t, server, "Deployment", deployment)
+	if response.Patch != nil {
+		t.Errorf("Expected no patch but got: %s", string(response.Patch))
+	}
+}
+
+func TestMutateDeploymentWithoutVaultMap(t *testing.T) {
+	server, cleanup := newTestServer(t)
+	defer cleanup()
+
+	response := admit(t, server, "Deployment", testDeployment())
+
+	if !response.Allowed {
+		t.Fatalf("Expected deployment to be allowed: %v", response.Result)
+	}
+	if response.Patch != nil {
+		t.Errorf("Expected no patch but got: %s", string(response.Patch))
+	}
+}
+
+func TestMutatePod(t *testing.T) {
+	server, cleanup := newTestServer(t, testVaultMap())
+	defer cleanup()
+
+	response := admit(t, server, "Pod", testPod())
+
+	if !response.Allowed {
+		t.Fatalf("Expected pod to be allowed: %v", response.Result)
+	}
+
+	var patch []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(response.Patch, &patch); err != nil {
+		t.Fatalf("Decoding patch resulted in an error: %v", err)
+	}
+	if len(patch) != 2 || patch[0].Path != "/spec" || patch[1].Path != "/metadata/annotations" {
+		t.Fatalf("Got unexpected patch: %s", string(response.Patch))
+	}
+
+	spec := corev1.PodSpec{}
+	if err := json.Unmarshal(patch[0].Value, &spec); err != nil {
+		t.Fatalf("Decoding pod spec resulted in an error: %v", err)
+	}
+	env := spec.Containers[0].Env
+	if len(env) != 1 || env[0].Name != "mysecret" || env[0].Value != "Password123" {
+		t.Errorf("Got unexpected environment variables: %v", env)
+	}
+
+	annotations := map[string]string{}
+	if err := json.Unmarshal(patch[1].Value, &annotations); err != nil {
+		t.Fatalf("Decoding annotations resulted in an error: %v", err)
+	}
+	if annotations[inject.InitializedAnnotation] != "true" {
+		t.Errorf("Expected pod to be flagged as initialized")
+	}
+}
+
+func TestMutatePodAlreadyInitialized(t *testing.T) {
+	server, cleanup := newTestServer(t, testVaultMap())
+	defer cleanup()
+
+	pod := testPod()
+	pod.Annotations = map[string]string{inject.InitializedAnnotation: "true"}
+	response := admit(t, server, "Pod", pod)
+
+	if !response.Allowed {
+		t.Fatalf("Expected pod to be allowed: %v", response.Result)
+	}
+	if response.Patch != nil {
+		t.Errorf("Expected no patch but got: %s", string(response.Patch))
+	}
+}
+
+func TestMutateDeploymentSelector(t *testing.T) {
+	other := testVaultMap()
+	other.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}}
+	server, cleanup := newTestServer(t, other)
+	defer cleanup()
+
+	response := admit(t, server, "Deployment", testDeployment())
+	if response.Patch != nil {
+		t.Errorf("Expected no patch but got: %s", string(response.Patch))
+	}
+}
+
+func TestMutateFailurePolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		allowed bool
+	}{
+		{"", false},
+		{model.FailurePolicyFail, false},
+		{model.FailurePolicyIgnore, true},
+	}
+	for _, test := range tests {
+		vaultmap := testVaultMap()
+		vaultmap.Spec.VaultPathPattern = "/v1/secret/{{.Missing}}"
+		vaultmap.Spec.FailurePolicy = test.policy
+		server, cleanup := newTestServer(t, vaultmap)
+
+		response := admit(t, server, "Deployment", testDeployment())
+		if response.Allowed != test.allowed {
+			t.Errorf("Got unexpected admission with failure policy %q: %v", test.policy, response.Allowed)
+		}
+		if response.Patch != nil {
+			t.Errorf("Expected no patch with failure policy %q but got: %s", test.policy, string(response.Patch))
+		}
+		cleanup()
+	}
+}
+
+func TestMutateUnsupportedKind(t *testing.T) {
+	server, cleanup := newTestServer(t, testVaultMap())
+	defer cleanup()
+
+	response := admit(t, server, "Service", &corev1.Service{})
+
+	if !response.Allowed {
+		t.Fatalf("Expected service to be allowed: %v", response.Result)
+	}
+	if response.Patch != nil {
+		t.Errorf("Expected no patch but got: %s", string(response.Patch))
+	}
+}
+
+func TestMutateInvalidContentType(t *testing.T) {
+	server, cleanup := newTestServer(t)
+	defer cleanup()
+
+	request := httptest.NewRequest("POST", "/mutate", bytes.NewBufferString("{}"))
+	request.Header.Set("Content-Type", "text/plain")
+	recorder := httptest.NewRecorder()
+	server.serveMutate(recorder, request)
+
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Got unexpected status code: %d", recorder.Code)
+	}
+}
+
+// admit sends an object to the mutating webhook and returns the response
+func admit(t *testing.T, server *Server, kind string, obj runtime.Object) *admissionv1beta1.AdmissionResponse {
+	return review(t, server.serveMutate, "/mutate", kind, obj)
+}
+
+// review sends an object to a webhook handler and returns the response
+func review(t *testing.T, handler http.HandlerFunc, path string, kind string, obj runtime.Object) *admissionv1beta1.AdmissionResponse {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("Encoding object resulted in an error: %v", err)
+	}
+	review := admissionv1beta1.AdmissionReview{
+		Request: &admissionv1beta1.AdmissionRequest{
+			UID:       "a-uid",
+			Kind:      metav1.GroupVersionKind{Kind: kind},
+			Namespace: "default",
+			Operation: admissionv1beta1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatalf("Encoding admission review resulted in an error: %v", err)
+	}
+
+	request := httptest.NewRequest("POST", path, bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Got unexpected status code: %d", recorder.Code)
+	}
+	response := admissionv1beta1.AdmissionReview{}
+	if err = json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Decoding admission review resulted in an error: %v", err)
+	}
+	if response.Response == nil || response.Response.UID != "a-uid" {
+		t.Fatalf("Got unexpected admission response: %v", response.Response)
+	}
+	return response.Response
+}
+
+// newTestServer creates a webhook server backed by a fake vault and the supplied vault maps
+func newTestServer(t *testing.T, maps ...*v1alpha1.VaultMap) (*Server, func()) {
+	vaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/secret/default/envprinter" && r.Header.Get("X-Vault-Token") == "atoken" {
+			w.Write([]byte(`{"data": {"mysecret": "Password123"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	config := &model.Config{VaultAddress: vaultServer.URL}
+	vaultClient, err := vaultclient.NewClient(config, map[string]string{})
+	if err != nil {
+		t.Fatalf("Creating vault client resulted in an error: %v", err)
+	}
+	tokens := vaultclient.NewTokenManager(vaultClient, &vaultclient.TokenAuthenticator{Token: "atoken"})
+	stopCh := make(chan struct{})
+	if err = tokens.Start(stopCh); err != nil {
+		t.Fatalf("Starting token manager resulted in an error: %v", err)
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	var objects []runtime.Object
+	for _, vaultmap := range maps {
+		indexer.Add(vaultmap)
+		objects = append(objects, vaultmap)
+	}
+
+	clusterIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	mapsLister := listers.NewVaultMapLister(indexer)
+	clusterMapsLister := listers.NewClusterVaultMapLister(clusterIndexer)
+
+	injector := inject.NewInjector(fake.NewSimpleClientset(), mapfake.NewSimpleClientset(objects...), mapsLister, clusterMapsLister, vaultClient, tokens, config, record.NewFakeRecorder(10))
+	return NewServer(injector), func() {
+		close(stopCh)
+		vaultServer.Close()
+	}
+}
+
+func testVaultMap() *v1alpha1.VaultMap {
+	return &v1alpha1.VaultMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "default-vaultmap"},
+		Spec: v1alpha1.MapSpec{
+			VaultPathPattern: "/v1/secret/{{.Namespace}}/{{.ContainerName}}",
+			KVVersion:        "1",
+			SecretsPublisher: "env",
+		},
+	}
+}
+
+func testDeployment() *v1beta1.Deployment {
+	return &v1beta1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "envprinter"},
+		Spec: v1beta1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "envprinter"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "envprinter", Image: "richardcase/envprinter:0.0.1"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func testPod() *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "envprinter-0",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "StatefulSet", Name: "envprinter", Controller: &controller},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "envprinter", Image: "richardcase/envprinter:0.0.1"},
+			},
+		},
+	}
+}
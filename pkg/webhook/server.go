@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/richardcase/vault-initializer/pkg/inject"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Server is a mutating admission webhook that injects secrets from vault. It
+// also validates vault maps and converts them between API versions.
+type Server struct {
+	injector *inject.Injector
+	synced   []cache.InformerSynced
+}
+
+// admitFunc handles an admission request and returns the response
+type admitFunc func(*admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse
+
+// NewServer returns a new webhook server. The server doesn't start handling
+// requests until the caches used by the injector have synced.
+func NewServer(injector *inject.Injector, synced ...cache.InformerSynced) *Server {
+	return &Server{
+		injector: injector,
+		synced:   synced,
+	}
+}
+
+// Run starts the HTTPS server and blocks until stopCh is closed
+func (s *Server) Run(address, certFile, keyFile string, stopCh <-chan struct{}) error {
+	glog.Info("Waiting for informer caches to sync")
+	if ok := cache.WaitForCacheSync(stopCh, s.synced...); !ok {
+		return fmt.Errorf("Failed to wait for caches to sync")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mutate", s.serveMutate)
+	mux.HandleFunc("/validate", s.serveValidate)
+	mux.HandleFunc("/convert", s.serveConvert)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := &http.Server{Addr: address, Handler: mux}
+
+	go func() {
+		<-stopCh
+		glog.Info("Shutting down webhook server")
+		_ = server.Shutdown(context.Background())
+	}()
+
+	glog.Infof("Starting webhook server on %s", address)
+	if err := server.ListenAndServeTLS(certFile, keyFile); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) serveMutate(w http.ResponseWriter, r *http.Request) {
+	serve(w, r, s.mutate)
+}
+
+func (s *Server) serveValidate(w http.ResponseWriter, r *http.Request) {
+	serve(w, r, s.validate)
+}
+
+// serve decodes an AdmissionReview, passes the request to the admit func and
+// writes the response back as an AdmissionReview
+func serve(w http.ResponseWriter, r *http.Request, admit admitFunc) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+		http.Error(w, fmt.Sprintf("Invalid Content-Type %s, expected application/json", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := admissionv1beta1.AdmissionReview{}
+	if err = json.Unmarshal(body, &review); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "AdmissionReview contains no request", http.StatusBadRequest)
+		return
+	}
+
+	response := admit(review.Request)
+	response.UID = review.Request.UID
+	review.Response = response
+	review.Request = nil
+
+	resp, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(resp); err != nil {
+		glog.Errorf("Error writing admission response: %v", err)
+	}
+}
+
+func allowedResponse() *admissionv1beta1.AdmissionResponse {
+	return &admissionv1beta1.AdmissionResponse{Allowed: true}
+}
+
+func errorResponse(err error) *admissionv1beta1.AdmissionResponse {
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"encoding/json"
+
+	"github.com/golang/glog"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1beta1"
+	"github.com/richardcase/vault-initializer/pkg/inject"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+)
+
+// validate rejects vault maps with templates that can't be resolved
+func (s *Server) validate(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	var err error
+	switch req.Kind.Version {
+	case v1beta1.SchemeGroupVersion.Version:
+		err = validateV1beta1(req)
+	default:
+		err = validateV1alpha1(req)
+	}
+
+	if err != nil {
+		glog.Infof("Rejecting %s %s: %v", req.Kind.Kind, req.Name, err)
+		return errorResponse(err)
+	}
+	return allowedResponse()
+}
+
+func validateV1alpha1(req *admissionv1beta1.AdmissionRequest) error {
+	switch req.Kind.Kind {
+	case "VaultMap":
+		vaultmap := &v1alpha1.VaultMap{}
+		if err := json.Unmarshal(req.Object.Raw, vaultmap); err != nil {
+			return err
+		}
+		return inject.ValidateMapSpec(&vaultmap.Spec)
+	case "ClusterVaultMap":
+		clusterMap := &v1alpha1.ClusterVaultMap{}
+		if err := json.Unmarshal(req.Object.Raw, clusterMap); err != nil {
+			return err
+		}
+		return inject.ValidateMapSpec(&clusterMap.Spec)
+	default:
+		glog.V(2).Infof("Ignoring validation request for unsupported kind %s", req.Kind.Kind)
+		return nil
+	}
+}
+
+func validateV1beta1(req *admissionv1beta1.AdmissionRequest) error {
+	switch req.Kind.Kind {
+	case "VaultMap":
+		vaultmap := &v1beta1.VaultMap{}
+		if err := json.Unmarshal(req.Object.Raw, vaultmap); err != nil {
+			return err
+		}
+		return inject.ValidateV1beta1MapSpec(&vaultmap.Spec)
+	case "ClusterVaultMap":
+		clusterMap := &v1beta1.ClusterVaultMap{}
+		if err := json.Unmarshal(req.Object.Raw, clusterMap); err != nil {
+			return err
+		}
+		return inject.ValidateV1beta1MapSpec(&clusterMap.Spec)
+	default:
+		glog.V(2).Infof("Ignoring validation request for unsupported kind %s", req.Kind.Kind)
+		return nil
+	}
+}
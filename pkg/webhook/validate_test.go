@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/richardcase/vault-initializer/pkg/apis/vaultinit/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateVaultMap(t *testing.T) {
+	server, cleanup := newTestServer(t)
+	defer cleanup()
+
+	response := review(t, server.serveValidate, "/validate", "VaultMap", testVaultMap())
+	if !response.Allowed {
+		t.Errorf("Expected vault map to be allowed: %v", response.Result)
+	}
+}
+
+func TestValidateVaultMapInvalidTemplate(t *testing.T) {
+	server, cleanup := newTestServer(t)
+	defer cleanup()
+
+	vaultmap := testVaultMap()
+	vaultmap.Spec.VaultPathPattern = "/v1/secret/{{.Namespace}}/{{.Container}}"
+
+	response := review(t, server.serveValidate, "/validate", "VaultMap", vaultmap)
+	if response.Allowed {
+		t.Fatalf("Expected vault map with an invalid template to be rejected")
+	}
+	if response.Result == nil || !strings.Contains(response.Result.Message, "spec.vaultPathPattern") {
+		t.Errorf("Got unexpected result: %v", response.Result)
+	}
+}
+
+func TestValidateClusterVaultMapInvalidTemplate(t *testing.T) {
+	server, cleanup := newTestServer(t)
+	defer cleanup()
+
+	clusterMap := &v1alpha1.ClusterVaultMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       testVaultMap().Spec,
+	}
+	clusterMap.Spec.SecretNamePattern = "{{.Namespace"
+
+	response := review(t, server.serveValidate, "/validate", "ClusterVaultMap", clusterMap)
+	if response.Allowed {
+		t.Fatalf("Expected cluster vault map with an invalid template to be rejected")
+	}
+	if response.Result == nil || !strings.Contains(response.Result.Message, "spec.secretNamePattern") {
+		t.Errorf("Got unexpected result: %v", response.Result)
+	}
+}